@@ -0,0 +1,115 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"example.com/main/render"
+	"example.com/main/translator"
+)
+
+// BabelePack names the Foundry compendium a category is exported to
+type BabelePack struct {
+	Collection string `json:"collection"` // compendium collection ID, e.g. "dnd5e.backgrounds"
+	Label      string `json:"label"`      // translated compendium label
+}
+
+// DefaultBabelePacks maps categories to the dnd5e system compendiums
+var DefaultBabelePacks = map[string]BabelePack{
+	"background": {Collection: "dnd5e.backgrounds", Label: "Backgrounds"},
+	"feat":       {Collection: "dnd5e.feats", Label: "Feats"},
+	"item":       {Collection: "dnd5e.items", Label: "Items"},
+	"race":       {Collection: "dnd5e.races", Label: "Races"},
+	"spell":      {Collection: "dnd5e.spells", Label: "Spells"},
+	"monster":    {Collection: "dnd5e.monsters", Label: "Monsters"},
+}
+
+// BabeleEntry is the translation of a single compendium document
+type BabeleEntry struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// BabeleCompendium is the content of one Babele translation file
+type BabeleCompendium struct {
+	Label   string                 `json:"label"`
+	Entries map[string]BabeleEntry `json:"entries"`
+}
+
+// BuildBabele converts translated entries into Babele compendium translations
+// keyed by collection ID. packs overrides DefaultBabelePacks per category.
+func BuildBabele(entries []translator.Entry, packs map[string]BabelePack) map[string]*BabeleCompendium {
+	compendiums := make(map[string]*BabeleCompendium)
+
+	for _, entry := range entries {
+		if entry.Translated == nil {
+			continue
+		}
+
+		pack, ok := packs[entry.Category]
+		if !ok {
+			pack, ok = DefaultBabelePacks[entry.Category]
+		}
+		if !ok {
+			continue
+		}
+
+		compendium, exists := compendiums[pack.Collection]
+		if !exists {
+			compendium = &BabeleCompendium{Label: pack.Label, Entries: make(map[string]BabeleEntry)}
+			compendiums[pack.Collection] = compendium
+		}
+
+		// Babele matches documents by their original name; the first source wins
+		originalName, _ := entry.Source["name"].(string)
+		if _, exists := compendium.Entries[originalName]; exists {
+			continue
+		}
+
+		name, _ := entry.Translated["name"].(string)
+		compendium.Entries[originalName] = BabeleEntry{
+			Name:        name,
+			Description: render.HTML(entry.Translated["entries"]),
+		}
+	}
+
+	return compendiums
+}
+
+// WriteBabele writes one <collection>.json Babele file per compendium into outputDir
+func WriteBabele(outputDir string, entries []translator.Entry, packs map[string]BabelePack) ([]string, error) {
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create babele directory: %w", err)
+	}
+
+	compendiums := BuildBabele(entries, packs)
+	collections := make([]string, 0, len(compendiums))
+	for collection := range compendiums {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+
+	var written []string
+	for _, collection := range collections {
+		compendium := compendiums[collection]
+		jsonData, err := json.MarshalIndent(compendium, "", "    ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal babele compendium %s: %w", collection, err)
+		}
+
+		outputPath := filepath.Join(outputDir, strings.ReplaceAll(collection, "/", "_")+".json")
+		err = ioutil.WriteFile(outputPath, jsonData, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write babele compendium %s: %w", collection, err)
+		}
+		written = append(written, outputPath)
+	}
+
+	return written, nil
+}
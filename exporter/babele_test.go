@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"testing"
+
+	"example.com/main/translator"
+)
+
+func TestBuildBabele(t *testing.T) {
+	entries := []translator.Entry{
+		{
+			Category: "background",
+			Key:      "Acolyte|XPHB",
+			Source:   map[string]interface{}{"name": "Acolyte", "source": "XPHB"},
+			Translated: map[string]interface{}{
+				"name":    "Аколіт",
+				"source":  "XPHB",
+				"entries": []interface{}{"Служитель {@deity Pelor}."},
+			},
+		},
+		{
+			Category: "background",
+			Key:      "Sage|XPHB",
+			Source:   map[string]interface{}{"name": "Sage", "source": "XPHB"},
+		},
+	}
+
+	packs := map[string]BabelePack{
+		"background": {Collection: "dnd5e.backgrounds", Label: "Передісторії"},
+	}
+
+	compendiums := BuildBabele(entries, packs)
+	compendium, exists := compendiums["dnd5e.backgrounds"]
+	if !exists {
+		t.Fatalf("Expected dnd5e.backgrounds compendium, got %v", compendiums)
+	}
+
+	if compendium.Label != "Передісторії" {
+		t.Errorf("Expected label 'Передісторії', got '%s'", compendium.Label)
+	}
+
+	if len(compendium.Entries) != 1 {
+		t.Errorf("Expected only translated entries, got %d", len(compendium.Entries))
+	}
+
+	acolyte := compendium.Entries["Acolyte"]
+	if acolyte.Name != "Аколіт" {
+		t.Errorf("Expected name 'Аколіт', got '%s'", acolyte.Name)
+	}
+	if acolyte.Description != "<p>Служитель Pelor.</p>" {
+		t.Errorf("Expected rendered description, got '%s'", acolyte.Description)
+	}
+}
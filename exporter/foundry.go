@@ -0,0 +1,205 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FoundryCompatibility lists the Foundry VTT core versions a module supports
+type FoundryCompatibility struct {
+	Minimum  string `json:"minimum,omitempty"`
+	Verified string `json:"verified,omitempty"`
+	Maximum  string `json:"maximum,omitempty"`
+}
+
+// FoundryModule describes the Foundry VTT module a translation is packaged as
+type FoundryModule struct {
+	ID            string               `json:"id"`
+	Title         string               `json:"title"`
+	Description   string               `json:"description,omitempty"`
+	Version       string               `json:"version"`
+	Authors       []string             `json:"authors,omitempty"`
+	Compatibility FoundryCompatibility `json:"compatibility"`
+	// Language is the locale listed in module.json, e.g. "uk"
+	Language string `json:"language,omitempty"`
+	// LanguageName is the display name of the locale, e.g. "Українська"
+	LanguageName string `json:"languageName,omitempty"`
+	// LanguageFile is a JSON file of UI strings packaged as languages/<Language>.json
+	LanguageFile string `json:"languageFile,omitempty"`
+}
+
+// Validate checks the fields Foundry requires in module.json
+func (m FoundryModule) Validate() error {
+	if m.ID == "" {
+		return fmt.Errorf("module id is empty")
+	}
+	// The id names the module directory, which packaging deletes first
+	if m.ID == "." || strings.Contains(m.ID, "..") || strings.ContainsAny(m.ID, `/\`) {
+		return fmt.Errorf("module id %q is not a plain directory name", m.ID)
+	}
+	if m.Title == "" {
+		return fmt.Errorf("module title is empty")
+	}
+	if m.Version == "" {
+		return fmt.Errorf("module version is empty")
+	}
+	if m.Language == "" {
+		return fmt.Errorf("module language is empty")
+	}
+	return nil
+}
+
+// manifest returns the module.json content
+func (m FoundryModule) manifest() map[string]interface{} {
+	manifest := map[string]interface{}{
+		"id":            m.ID,
+		"title":         m.Title,
+		"description":   m.Description,
+		"version":       m.Version,
+		"compatibility": m.Compatibility,
+	}
+
+	var authors []interface{}
+	for _, author := range m.Authors {
+		authors = append(authors, map[string]interface{}{"name": author})
+	}
+	if len(authors) > 0 {
+		manifest["authors"] = authors
+	}
+
+	name := m.LanguageName
+	if name == "" {
+		name = m.Language
+	}
+	manifest["languages"] = []interface{}{
+		map[string]interface{}{
+			"lang": m.Language,
+			"name": name,
+			"path": "languages/" + m.Language + ".json",
+		},
+	}
+
+	return manifest
+}
+
+// PackageFoundryModule creates <outputPath>/<id> with module.json, the translated
+// data files and the language file, empty unless one is given, and zips it to <outputPath>/<id>-<version>.zip
+func PackageFoundryModule(module FoundryModule, exportPath string, files []string, outputPath string) (string, string, error) {
+	err := module.Validate()
+	if err != nil {
+		return "", "", fmt.Errorf("invalid module: %w", err)
+	}
+
+	moduleDir := filepath.Join(outputPath, module.ID)
+	err = os.RemoveAll(moduleDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to clean module directory: %w", err)
+	}
+
+	// Copy the translated data files
+	for _, file := range files {
+		err = copyFile(filepath.Join(exportPath, file), filepath.Join(moduleDir, "data", file))
+		if err != nil {
+			return "", "", fmt.Errorf("failed to copy %s: %w", file, err)
+		}
+	}
+
+	// module.json always lists the language, so it gets an empty file without UI strings
+	languagePath := filepath.Join(moduleDir, "languages", module.Language+".json")
+	if module.LanguageFile != "" {
+		err = copyFile(module.LanguageFile, languagePath)
+	} else if err = os.MkdirAll(filepath.Dir(languagePath), 0755); err == nil {
+		err = ioutil.WriteFile(languagePath, []byte("{}\n"), 0644)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write language file: %w", err)
+	}
+
+	jsonData, err := json.MarshalIndent(module.manifest(), "", "    ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal module.json: %w", err)
+	}
+	err = ioutil.WriteFile(filepath.Join(moduleDir, "module.json"), jsonData, 0644)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write module.json: %w", err)
+	}
+
+	zipPath := filepath.Join(outputPath, module.ID+"-"+module.Version+".zip")
+	err = zipDirectory(moduleDir, zipPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to zip module: %w", err)
+	}
+
+	return moduleDir, zipPath, nil
+}
+
+// copyFile copies src to dst, creating the parent directories of dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	err = os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// zipDirectory writes every file below dir into a zip archive with paths relative to dir
+func zipDirectory(dir, zipPath string) error {
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	archive := zip.NewWriter(out)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		writer, err := archive.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		_, err = io.Copy(writer, in)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,152 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestPackageFoundryModule(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_foundry")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	exportDir := filepath.Join(tempDir, "export")
+	writeTranslatedFile(t, exportDir, "backgrounds.json", map[string]interface{}{
+		"background": []interface{}{map[string]interface{}{"name": "Аколіт", "source": "XPHB"}},
+	})
+	writeTranslatedFile(t, exportDir, "spells/spells-xphb.json", map[string]interface{}{
+		"spell": []interface{}{},
+	})
+	languageFile := filepath.Join(tempDir, "uk.json")
+	writeTranslatedFile(t, tempDir, "uk.json", map[string]interface{}{"TRANSLATION.Title": "Переклад"})
+
+	module := FoundryModule{
+		ID:            "plutonium-uk",
+		Title:         "Plutonium: Ukrainian",
+		Version:       "1.2.0",
+		Authors:       []string{"Team"},
+		Compatibility: FoundryCompatibility{Minimum: "11", Verified: "12"},
+		Language:      "uk",
+		LanguageName:  "Українська",
+		LanguageFile:  languageFile,
+	}
+
+	outputDir := filepath.Join(tempDir, "dist")
+	moduleDir, zipPath, err := PackageFoundryModule(module, exportDir, []string{"backgrounds.json", "spells/spells-xphb.json"}, outputDir)
+	if err != nil {
+		t.Fatalf("Failed to package module: %v", err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(moduleDir, "module.json"))
+	if err != nil {
+		t.Fatalf("Failed to read module.json: %v", err)
+	}
+
+	var manifest map[string]interface{}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		t.Fatalf("Failed to unmarshal module.json: %v", err)
+	}
+
+	if manifest["id"] != "plutonium-uk" || manifest["version"] != "1.2.0" {
+		t.Errorf("Expected id and version in module.json, got %v", manifest)
+	}
+
+	languages := manifest["languages"].([]interface{})
+	language := languages[0].(map[string]interface{})
+	if language["lang"] != "uk" || language["path"] != "languages/uk.json" {
+		t.Errorf("Expected uk language entry, got %v", language)
+	}
+
+	if filepath.Base(zipPath) != "plutonium-uk-1.2.0.zip" {
+		t.Errorf("Expected zip named after id and version, got %s", zipPath)
+	}
+
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer archive.Close()
+
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+
+	expected := []string{"data/backgrounds.json", "data/spells/spells-xphb.json", "languages/uk.json", "module.json"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected zip entries %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected zip entry %s, got %s", expected[i], names[i])
+		}
+	}
+}
+
+func TestPackageFoundryModuleRequiresVersion(t *testing.T) {
+	_, _, err := PackageFoundryModule(FoundryModule{ID: "plutonium-uk", Title: "Plutonium"}, "", nil, "")
+	if err == nil {
+		t.Errorf("Expected error for module without version")
+	}
+}
+
+func TestPackageFoundryModuleRejectsUnsafeID(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_foundry_id")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	outputDir := filepath.Join(tempDir, "dist")
+	keep := filepath.Join(tempDir, "keep")
+	writeTranslatedFile(t, keep, "notes.json", map[string]interface{}{})
+
+	for _, id := range []string{".", "..", "../keep", "a/b", `a\b`} {
+		module := FoundryModule{ID: id, Title: "Plutonium", Version: "1.0.0", Language: "uk"}
+		if _, _, err := PackageFoundryModule(module, "", nil, outputDir); err == nil {
+			t.Errorf("Expected error for module id %q", id)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(keep, "notes.json")); err != nil {
+		t.Errorf("Expected directories outside the output to be kept, got %v", err)
+	}
+}
+
+func TestPackageFoundryModuleListsLanguageWithoutFile(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_foundry_language")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	module := FoundryModule{ID: "plutonium-uk", Title: "Plutonium", Version: "1.0.0", Language: "uk"}
+	moduleDir, _, err := PackageFoundryModule(module, "", nil, filepath.Join(tempDir, "dist"))
+	if err != nil {
+		t.Fatalf("Failed to package module: %v", err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(moduleDir, "module.json"))
+	if err != nil {
+		t.Fatalf("Failed to read module.json: %v", err)
+	}
+	var manifest struct {
+		Languages []map[string]string `json:"languages"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		t.Fatalf("Failed to unmarshal module.json: %v", err)
+	}
+	if len(manifest.Languages) != 1 || manifest.Languages[0]["lang"] != "uk" || manifest.Languages[0]["name"] != "uk" {
+		t.Fatalf("Expected the uk language entry, got %v", manifest.Languages)
+	}
+	if _, err := os.Stat(filepath.Join(moduleDir, manifest.Languages[0]["path"])); err != nil {
+		t.Errorf("Expected the listed language file to exist, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package exporter turns translated data files into formats other tools load
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// HomebrewSource describes a source declared in the homebrew _meta block
+type HomebrewSource struct {
+	From         string   `json:"from"` // original source the translated entities come from, e.g. "XPHB"
+	JSON         string   `json:"json"` // source ID of the translated copies, e.g. "XPHBuk"
+	Abbreviation string   `json:"abbreviation,omitempty"`
+	Full         string   `json:"full,omitempty"`
+	Authors      []string `json:"authors,omitempty"`
+	ConvertedBy  []string `json:"convertedBy,omitempty"`
+	Version      string   `json:"version,omitempty"`
+	URL          string   `json:"url,omitempty"`
+}
+
+// HomebrewOptions configures the homebrew file
+type HomebrewOptions struct {
+	// Sources declared in _meta; entities from sources without an entry get a generated one
+	Sources []HomebrewSource
+	// Resource moves translated entities to the declared source IDs so they
+	// coexist with the English originals instead of replacing them
+	Resource bool
+	// Language is the locale marker written to _meta, e.g. "uk"
+	Language string
+	// DateAdded and DateLastModified are written to _meta when set. They are
+	// never taken from the clock, so unchanged inputs give the same file.
+	// DateAdded defaults to DateLastModified.
+	DateAdded        time.Time
+	DateLastModified time.Time
+}
+
+// source returns the declared source for an original source ID, generating one when missing
+func (o HomebrewOptions) source(from string) HomebrewSource {
+	for _, source := range o.Sources {
+		if source.From == from {
+			return source
+		}
+	}
+
+	id := from
+	if o.Language != "" {
+		id = from + o.Language
+	}
+	return HomebrewSource{
+		From:         from,
+		JSON:         id,
+		Abbreviation: id,
+		Full:         fmt.Sprintf("%s (%s)", from, o.Language),
+		Version:      "1.0.0",
+	}
+}
+
+// BuildHomebrew combines translated data files into a single homebrew document
+func BuildHomebrew(exportPath string, files []string, options HomebrewOptions) (map[string]interface{}, error) {
+	homebrew := make(map[string]interface{})
+	usedSources := make(map[string]bool)
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(filepath.Join(exportPath, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read translated file %s: %w", file, err)
+		}
+
+		var translated map[string]interface{}
+		err = json.Unmarshal(data, &translated)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal translated file %s: %w", file, err)
+		}
+
+		for key, value := range translated {
+			if key == "_meta" {
+				continue
+			}
+			entities, ok := value.([]interface{})
+			if !ok {
+				continue
+			}
+
+			for _, entity := range entities {
+				entityMap, ok := entity.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if source, ok := entityMap["source"].(string); ok {
+					usedSources[source] = true
+					if options.Resource {
+						entityMap["source"] = options.source(source).JSON
+					}
+				}
+			}
+
+			existing, _ := homebrew[key].([]interface{})
+			homebrew[key] = append(existing, entities...)
+		}
+	}
+
+	// Declare every source used by the exported entities
+	var sourceIDs []string
+	for source := range usedSources {
+		sourceIDs = append(sourceIDs, source)
+	}
+	sort.Strings(sourceIDs)
+
+	var sources []interface{}
+	for _, from := range sourceIDs {
+		source := options.source(from)
+		declared := map[string]interface{}{
+			"json":         source.JSON,
+			"abbreviation": source.Abbreviation,
+			"full":         source.Full,
+			"version":      source.Version,
+		}
+		if !options.Resource {
+			declared["json"] = from
+		}
+		if declared["abbreviation"] == "" {
+			declared["abbreviation"] = declared["json"]
+		}
+		if len(source.Authors) > 0 {
+			declared["authors"] = source.Authors
+		}
+		if len(source.ConvertedBy) > 0 {
+			declared["convertedBy"] = source.ConvertedBy
+		}
+		if source.URL != "" {
+			declared["url"] = source.URL
+		}
+		sources = append(sources, declared)
+	}
+
+	meta := map[string]interface{}{
+		"sources":  sources,
+		"language": options.Language,
+	}
+	if !options.DateLastModified.IsZero() {
+		meta["dateLastModified"] = options.DateLastModified.Unix()
+		meta["dateAdded"] = options.DateLastModified.Unix()
+	}
+	if !options.DateAdded.IsZero() {
+		meta["dateAdded"] = options.DateAdded.Unix()
+	}
+	homebrew["_meta"] = meta
+
+	return homebrew, nil
+}
+
+// WriteHomebrew builds the homebrew document and writes it to outputPath
+func WriteHomebrew(exportPath string, files []string, outputPath string, options HomebrewOptions) error {
+	homebrew, err := BuildHomebrew(exportPath, files, options)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.MarshalIndent(homebrew, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal homebrew: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(outputPath), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create homebrew directory: %w", err)
+	}
+
+	err = ioutil.WriteFile(outputPath, jsonData, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write homebrew: %w", err)
+	}
+
+	return nil
+}
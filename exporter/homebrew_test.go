@@ -0,0 +1,156 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTranslatedFile(t *testing.T, dir, file string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Failed to marshal %s: %v", file, err)
+	}
+	path := filepath.Join(dir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", file, err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", file, err)
+	}
+}
+
+func TestBuildHomebrew(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_homebrew")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeTranslatedFile(t, tempDir, "backgrounds.json", map[string]interface{}{
+		"_meta": map[string]interface{}{"internalCopies": []interface{}{"background"}},
+		"background": []interface{}{
+			map[string]interface{}{"name": "Аколіт", "source": "XPHB"},
+		},
+	})
+	writeTranslatedFile(t, tempDir, "spells/spells-phb.json", map[string]interface{}{
+		"spell": []interface{}{
+			map[string]interface{}{"name": "Світло", "source": "PHB"},
+		},
+	})
+
+	options := HomebrewOptions{
+		Sources: []HomebrewSource{
+			{From: "XPHB", JSON: "XPHBuk", Full: "Player's Handbook (2024), Ukrainian", Authors: []string{"Team"}, Version: "1.2.0"},
+		},
+		Resource: true,
+		Language: "uk",
+	}
+
+	homebrew, err := BuildHomebrew(tempDir, []string{"backgrounds.json", "spells/spells-phb.json"}, options)
+	if err != nil {
+		t.Fatalf("Failed to build homebrew: %v", err)
+	}
+
+	background := homebrew["background"].([]interface{})[0].(map[string]interface{})
+	if background["source"] != "XPHBuk" {
+		t.Errorf("Expected background to be re-sourced to 'XPHBuk', got '%s'", background["source"])
+	}
+
+	spell := homebrew["spell"].([]interface{})[0].(map[string]interface{})
+	if spell["source"] != "PHBuk" {
+		t.Errorf("Expected spell to get a generated source 'PHBuk', got '%s'", spell["source"])
+	}
+
+	meta := homebrew["_meta"].(map[string]interface{})
+	if meta["language"] != "uk" {
+		t.Errorf("Expected language marker 'uk', got '%v'", meta["language"])
+	}
+
+	sources := meta["sources"].([]interface{})
+	if len(sources) != 2 {
+		t.Fatalf("Expected 2 declared sources, got %d", len(sources))
+	}
+
+	phb := sources[0].(map[string]interface{})
+	xphb := sources[1].(map[string]interface{})
+	if phb["json"] != "PHBuk" || xphb["json"] != "XPHBuk" {
+		t.Errorf("Expected sources PHBuk and XPHBuk, got %v and %v", phb["json"], xphb["json"])
+	}
+	if xphb["full"] != "Player's Handbook (2024), Ukrainian" || xphb["version"] != "1.2.0" {
+		t.Errorf("Expected configured XPHB source details, got %v", xphb)
+	}
+}
+
+func TestBuildHomebrewWithoutResource(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_homebrew")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeTranslatedFile(t, tempDir, "backgrounds.json", map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Аколіт", "source": "XPHB"},
+		},
+	})
+
+	homebrew, err := BuildHomebrew(tempDir, []string{"backgrounds.json"}, HomebrewOptions{Language: "uk"})
+	if err != nil {
+		t.Fatalf("Failed to build homebrew: %v", err)
+	}
+
+	background := homebrew["background"].([]interface{})[0].(map[string]interface{})
+	if background["source"] != "XPHB" {
+		t.Errorf("Expected background to keep source 'XPHB', got '%s'", background["source"])
+	}
+
+	source := homebrew["_meta"].(map[string]interface{})["sources"].([]interface{})[0].(map[string]interface{})
+	if source["json"] != "XPHB" {
+		t.Errorf("Expected declared source 'XPHB', got '%v'", source["json"])
+	}
+}
+
+func TestBuildHomebrewIsReproducible(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_homebrew")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeTranslatedFile(t, tempDir, "backgrounds.json", map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Аколіт", "source": "XPHB"},
+		},
+	})
+
+	modified := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	options := HomebrewOptions{Language: "uk", DateLastModified: modified}
+	build := func() []byte {
+		homebrew, err := BuildHomebrew(tempDir, []string{"backgrounds.json"}, options)
+		if err != nil {
+			t.Fatalf("Failed to build homebrew: %v", err)
+		}
+		data, err := json.Marshal(homebrew)
+		if err != nil {
+			t.Fatalf("Failed to marshal homebrew: %v", err)
+		}
+		return data
+	}
+
+	first := build()
+	if second := build(); !bytes.Equal(first, second) {
+		t.Errorf("Expected the same homebrew for the same inputs\nfirst: %s\nsecond: %s", first, second)
+	}
+
+	var homebrew map[string]map[string]interface{}
+	json.Unmarshal(first, &homebrew)
+	meta := homebrew["_meta"]
+	if meta["dateAdded"] != float64(modified.Unix()) || meta["dateLastModified"] != float64(modified.Unix()) {
+		t.Errorf("Expected both dates from the options, got %v and %v", meta["dateAdded"], meta["dateLastModified"])
+	}
+}
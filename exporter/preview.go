@@ -0,0 +1,165 @@
+package exporter
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"example.com/main/render"
+	"example.com/main/translator"
+)
+
+// PreviewFormat selects the page format of a preview
+type PreviewFormat string
+
+const (
+	PreviewHTML     PreviewFormat = "html"
+	PreviewMarkdown PreviewFormat = "md"
+)
+
+// previewStyle lays out the source and the translation side by side
+const previewStyle = `body{font-family:sans-serif;margin:2em auto;max-width:80em;padding:0 1em}
+.columns{display:grid;grid-template-columns:1fr 1fr;gap:2em}
+.untranslated{color:#999}
+aside{background:#f4f1e8;border-left:4px solid #b9a46a;padding:.5em 1em}
+blockquote{font-style:italic}
+table{border-collapse:collapse}td,th{border:1px solid #ccc;padding:.2em .5em}`
+
+// previewSlugPattern matches the characters replaced in preview file names
+var previewSlugPattern = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// WritePreview writes a static page per entity showing the source entry next
+// to its translation, plus an index page linking them by category. It returns
+// the path of the index page.
+func WritePreview(outputDir string, entries []translator.Entry, format PreviewFormat) (string, error) {
+	if format != PreviewHTML && format != PreviewMarkdown {
+		return "", fmt.Errorf("unknown preview format %q", format)
+	}
+
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create preview directory: %w", err)
+	}
+
+	var index []string
+	category := ""
+	for _, entry := range entries {
+		page := previewPage(entry, format)
+		pagePath := filepath.Join(outputDir, entry.Category, previewFileName(entry, format))
+		err = os.MkdirAll(filepath.Dir(pagePath), 0755)
+		if err != nil {
+			return "", fmt.Errorf("failed to create preview directory: %w", err)
+		}
+		err = ioutil.WriteFile(pagePath, []byte(page), 0644)
+		if err != nil {
+			return "", fmt.Errorf("failed to write preview of %s: %w", entry.Key, err)
+		}
+
+		if entry.Category != category {
+			category = entry.Category
+			index = append(index, previewHeading(category, format))
+		}
+		index = append(index, previewIndexLine(entry, format))
+	}
+
+	indexPath := filepath.Join(outputDir, "index."+string(format))
+	err = ioutil.WriteFile(indexPath, []byte(previewDocument("Preview", strings.Join(index, "\n"), format)), 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write preview index: %w", err)
+	}
+
+	return indexPath, nil
+}
+
+// previewFileName returns the page file name of an entity within its category directory
+func previewFileName(entry translator.Entry, format PreviewFormat) string {
+	slug := strings.Trim(previewSlugPattern.ReplaceAllString(strings.ToLower(entry.Key), "-"), "-")
+	return slug + "." + string(format)
+}
+
+// previewPage renders the page of a single entity
+func previewPage(entry translator.Entry, format PreviewFormat) string {
+	original, _ := entry.Source["name"].(string)
+	title := original
+	if entry.Translated != nil {
+		if name, ok := entry.Translated["name"].(string); ok {
+			title = name
+		}
+	}
+
+	if format == PreviewMarkdown {
+		translation := "*Not translated*"
+		if entry.Translated != nil {
+			translation = render.Markdown(entry.Translated["entries"])
+		}
+		body := fmt.Sprintf("*%s · %s*\n\n## %s\n\n%s\n\n## %s\n\n%s\n\n[Index](../index.md)",
+			render.InlineMarkdown(entry.Key), render.InlineMarkdown(entry.File),
+			render.InlineMarkdown(original), render.Markdown(entry.Source["entries"]),
+			render.InlineMarkdown(title), translation)
+		return previewDocument(title, body, format)
+	}
+
+	translation := `<p class="untranslated">Not translated</p>`
+	if entry.Translated != nil {
+		translation = render.HTML(entry.Translated["entries"])
+	}
+	body := fmt.Sprintf(`<p><a href="../index.html">Index</a> · %s · %s</p>
+<div class="columns">
+<section><h2>%s</h2>%s</section>
+<section><h2>%s</h2>%s</section>
+</div>`,
+		html.EscapeString(entry.Key), html.EscapeString(entry.File),
+		render.InlineHTML(original), render.HTML(entry.Source["entries"]),
+		render.InlineHTML(title), translation)
+	return previewDocument(title, body, format)
+}
+
+// previewHeading renders the index heading of a category
+func previewHeading(category string, format PreviewFormat) string {
+	if format == PreviewMarkdown {
+		return "\n## " + category + "\n"
+	}
+	return "<h2>" + html.EscapeString(category) + "</h2>"
+}
+
+// previewIndexLine renders the index link to the page of an entity
+func previewIndexLine(entry translator.Entry, format PreviewFormat) string {
+	link := entry.Category + "/" + previewFileName(entry, format)
+	name := entry.Key
+	if entry.Translated != nil {
+		if translated, ok := entry.Translated["name"].(string); ok {
+			name = translated + " — " + entry.Key
+		}
+	}
+
+	if format == PreviewMarkdown {
+		status := ""
+		if entry.Translated == nil {
+			status = " *(untranslated)*"
+		}
+		return fmt.Sprintf("- [%s](%s)%s", render.InlineMarkdown(name), link, status)
+	}
+
+	class := ""
+	if entry.Translated == nil {
+		class = ` class="untranslated"`
+	}
+	return fmt.Sprintf(`<p%s><a href="%s">%s</a></p>`, class, html.EscapeString(link), html.EscapeString(name))
+}
+
+// previewDocument wraps a page body into a complete document
+func previewDocument(title, body string, format PreviewFormat) string {
+	if format == PreviewMarkdown {
+		return "# " + render.InlineMarkdown(title) + "\n\n" + strings.TrimSpace(body) + "\n"
+	}
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>%s</title><style>%s</style></head>
+<body><h1>%s</h1>
+%s
+</body></html>
+`, html.EscapeString(title), previewStyle, render.InlineHTML(title), body)
+}
@@ -0,0 +1,82 @@
+package exporter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/main/translator"
+)
+
+func TestWritePreview(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_preview")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	entries := []translator.Entry{
+		{
+			Category: "background",
+			File:     "backgrounds.json",
+			Key:      "Acolyte|XPHB",
+			Source:   map[string]interface{}{"name": "Acolyte", "source": "XPHB", "entries": []interface{}{"Temple service."}},
+			Translated: map[string]interface{}{
+				"name":    "Аколіт",
+				"source":  "XPHB",
+				"entries": []interface{}{"Служіння у {@b храмі}."},
+			},
+		},
+		{
+			Category: "background",
+			File:     "backgrounds.json",
+			Key:      "Sage|XPHB",
+			Source:   map[string]interface{}{"name": "Sage", "source": "XPHB", "entries": []interface{}{"Years of study."}},
+		},
+	}
+
+	indexPath, err := WritePreview(tempDir, entries, PreviewHTML)
+	if err != nil {
+		t.Fatalf("Failed to write preview: %v", err)
+	}
+	if indexPath != filepath.Join(tempDir, "index.html") {
+		t.Errorf("Expected index.html, got %s", indexPath)
+	}
+
+	index, err := ioutil.ReadFile(indexPath)
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if !strings.Contains(string(index), `<a href="background/acolyte-xphb.html">Аколіт — Acolyte|XPHB</a>`) {
+		t.Errorf("Expected the index to link the translated entity, got %s", index)
+	}
+	if !strings.Contains(string(index), `<p class="untranslated"><a href="background/sage-xphb.html">`) {
+		t.Errorf("Expected the index to mark untranslated entities, got %s", index)
+	}
+
+	page, err := ioutil.ReadFile(filepath.Join(tempDir, "background", "acolyte-xphb.html"))
+	if err != nil {
+		t.Fatalf("Failed to read page: %v", err)
+	}
+	if !strings.Contains(string(page), "<p>Temple service.</p>") || !strings.Contains(string(page), "<p>Служіння у <strong>храмі</strong>.</p>") {
+		t.Errorf("Expected source and translation side by side, got %s", page)
+	}
+
+	_, err = WritePreview(tempDir, entries, PreviewMarkdown)
+	if err != nil {
+		t.Fatalf("Failed to write markdown preview: %v", err)
+	}
+	page, err = ioutil.ReadFile(filepath.Join(tempDir, "background", "sage-xphb.md"))
+	if err != nil {
+		t.Fatalf("Failed to read markdown page: %v", err)
+	}
+	if !strings.HasPrefix(string(page), "# Sage\n") || !strings.Contains(string(page), "*Not translated*") {
+		t.Errorf("Unexpected markdown page: %s", page)
+	}
+
+	if _, err := WritePreview(tempDir, entries, "pdf"); err == nil {
+		t.Errorf("Expected an unknown format to be rejected")
+	}
+}
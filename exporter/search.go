@@ -0,0 +1,97 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"example.com/main/translator"
+)
+
+// SearchEntry is one translated entity in the search index
+type SearchEntry struct {
+	Name     string      `json:"name"`              // translated name
+	Original string      `json:"original"`          // original English name
+	Aliases  []string    `json:"aliases,omitempty"` // alternative names of the entity
+	Source   string      `json:"source"`
+	Page     interface{} `json:"page,omitempty"`
+	Category string      `json:"category"`
+	Hash     string      `json:"hash"` // 5etools URL hash of the entity, e.g. "acolyte_xphb"
+}
+
+// BuildSearchIndex lists the translated entries with the names users may search for,
+// so an entity is found by its translated name as well as by its English one
+func BuildSearchIndex(entries []translator.Entry) []SearchEntry {
+	index := []SearchEntry{}
+	seen := make(map[string]bool)
+
+	for _, entry := range entries {
+		if entry.Translated == nil || seen[entry.Category+"|"+entry.Key] {
+			continue
+		}
+		seen[entry.Category+"|"+entry.Key] = true
+
+		original, _ := entry.Source["name"].(string)
+		source, _ := entry.Source["source"].(string)
+		name, _ := entry.Translated["name"].(string)
+
+		searchEntry := SearchEntry{
+			Name:     name,
+			Original: original,
+			Source:   source,
+			Page:     entry.Translated["page"],
+			Category: entry.Category,
+			Hash:     urlHash(original, source),
+		}
+		if aliases, ok := entry.Translated["alias"].([]interface{}); ok {
+			for _, alias := range aliases {
+				if text, ok := alias.(string); ok && text != "" {
+					searchEntry.Aliases = append(searchEntry.Aliases, text)
+				}
+			}
+		}
+
+		index = append(index, searchEntry)
+	}
+
+	return index
+}
+
+// WriteSearchIndex writes the search index of the translated entries to outputPath
+func WriteSearchIndex(outputPath string, entries []translator.Entry) (int, error) {
+	index := BuildSearchIndex(entries)
+
+	jsonData, err := json.MarshalIndent(index, "", "    ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal search index: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(outputPath), 0755)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create search index directory: %w", err)
+	}
+
+	err = ioutil.WriteFile(outputPath, jsonData, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write search index: %w", err)
+	}
+
+	return len(index), nil
+}
+
+// urlHash returns the hash 5etools pages use to link an entity
+func urlHash(name, source string) string {
+	return encodeURIComponent(strings.ToLower(name)) + "_" + encodeURIComponent(strings.ToLower(source))
+}
+
+// uriComponentReplacer undoes the escapes url.QueryEscape adds beyond JavaScript's encodeURIComponent
+var uriComponentReplacer = strings.NewReplacer("+", "%20", "%21", "!", "%27", "'", "%28", "(", "%29", ")", "%2A", "*")
+
+// encodeURIComponent escapes text like JavaScript's encodeURIComponent
+func encodeURIComponent(text string) string {
+	return uriComponentReplacer.Replace(url.QueryEscape(text))
+}
@@ -0,0 +1,87 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"example.com/main/translator"
+)
+
+func TestBuildSearchIndex(t *testing.T) {
+	entries := []translator.Entry{
+		{
+			Category: "background",
+			Key:      "Acolyte|XPHB",
+			Source:   map[string]interface{}{"name": "Acolyte", "source": "XPHB", "page": 178.0},
+			Translated: map[string]interface{}{
+				"name":   "Аколіт",
+				"source": "XPHB",
+				"page":   178.0,
+				"alias":  []interface{}{"Послушник", ""},
+			},
+		},
+		{
+			Category: "background",
+			Key:      "Sage|XPHB",
+			Source:   map[string]interface{}{"name": "Sage", "source": "XPHB"},
+		},
+		{
+			Category:   "spell",
+			Key:        "Tasha's Hideous Laughter|XPHB",
+			Source:     map[string]interface{}{"name": "Tasha's Hideous Laughter", "source": "XPHB"},
+			Translated: map[string]interface{}{"name": "Жахливий сміх Таші", "source": "XPHB"},
+		},
+	}
+
+	index := BuildSearchIndex(entries)
+	if len(index) != 2 {
+		t.Fatalf("Expected only translated entries, got %d", len(index))
+	}
+
+	acolyte := index[0]
+	if acolyte.Name != "Аколіт" || acolyte.Original != "Acolyte" || acolyte.Source != "XPHB" || acolyte.Category != "background" {
+		t.Errorf("Unexpected search entry %+v", acolyte)
+	}
+	if acolyte.Page != 178.0 {
+		t.Errorf("Expected page 178, got %v", acolyte.Page)
+	}
+	if len(acolyte.Aliases) != 1 || acolyte.Aliases[0] != "Послушник" {
+		t.Errorf("Expected alias 'Послушник', got %v", acolyte.Aliases)
+	}
+	if acolyte.Hash != "acolyte_xphb" {
+		t.Errorf("Expected hash 'acolyte_xphb', got '%s'", acolyte.Hash)
+	}
+
+	if index[1].Hash != "tasha's%20hideous%20laughter_xphb" {
+		t.Errorf("Expected an encodeURIComponent hash, got '%s'", index[1].Hash)
+	}
+}
+
+func TestWriteSearchIndex(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_search")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	outputPath := filepath.Join(tempDir, "search", "index-uk.json")
+	count, err := WriteSearchIndex(outputPath, nil)
+	if err != nil {
+		t.Fatalf("Failed to write search index: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no entries, got %d", count)
+	}
+
+	data, err := ioutil.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read search index: %v", err)
+	}
+	var index []SearchEntry
+	if err := json.Unmarshal(data, &index); err != nil || index == nil {
+		t.Errorf("Expected an empty JSON array, got %s", data)
+	}
+}
@@ -0,0 +1,142 @@
+package layouts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// directoryBrowser is a built-in folder picker screen, since the platform
+// file explorer can only choose files
+type directoryBrowser struct {
+	nav      *Navigator
+	onSelect func(dir string)
+
+	title   string
+	dir     string
+	subdirs []string
+	err     error
+
+	subdirButtons []widget.Clickable
+	list          widget.List
+	upButton      widget.Clickable
+	selectButton  widget.Clickable
+	cancelButton  widget.Clickable
+}
+
+// newDirectoryBrowser creates a browser starting at start, or at its nearest
+// existing parent; an empty start opens the home directory. onSelect receives
+// the chosen directory.
+func newDirectoryBrowser(title, start string, onSelect func(dir string)) *directoryBrowser {
+	b := &directoryBrowser{title: title, onSelect: onSelect}
+	b.list.Axis = layout.Vertical
+
+	if start == "" {
+		start, _ = os.UserHomeDir()
+	}
+	start, _ = filepath.Abs(start)
+	for {
+		if info, err := os.Stat(start); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(start)
+		if parent == start {
+			break
+		}
+		start = parent
+	}
+	b.open(start)
+	return b
+}
+
+func (b *directoryBrowser) Init(nav *Navigator) {
+	b.nav = nav
+}
+
+// open lists the subdirectories of dir, skipping hidden ones
+func (b *directoryBrowser) open(dir string) {
+	b.dir = dir
+	b.subdirs = b.subdirs[:0]
+	b.list.Position = layout.Position{}
+
+	files, err := ioutil.ReadDir(dir)
+	b.err = err
+	for _, file := range files {
+		if file.IsDir() && !strings.HasPrefix(file.Name(), ".") {
+			b.subdirs = append(b.subdirs, file.Name())
+		}
+	}
+	sort.Slice(b.subdirs, func(i, j int) bool {
+		return strings.ToLower(b.subdirs[i]) < strings.ToLower(b.subdirs[j])
+	})
+	b.subdirButtons = make([]widget.Clickable, len(b.subdirs))
+}
+
+// Layout draws the browser and goes back once the user selects a directory or cancels
+func (b *directoryBrowser) Layout(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	if b.selectButton.Clicked(gtx) {
+		b.onSelect(b.dir)
+		b.nav.Pop()
+	}
+	if b.cancelButton.Clicked(gtx) {
+		b.nav.Pop()
+	}
+	if b.upButton.Clicked(gtx) {
+		b.open(filepath.Dir(b.dir))
+	}
+	for i := range b.subdirButtons {
+		if b.subdirButtons[i].Clicked(gtx) {
+			b.open(filepath.Join(b.dir, b.subdirs[i]))
+			break
+		}
+	}
+
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(material.H6(theme, b.title).Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if filepath.Dir(b.dir) == b.dir {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(theme, &b.upButton, "Up").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Flexed(1, material.Body1(theme, b.dir).Layout),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if b.err != nil {
+					return material.Body2(theme, fmt.Sprintf("Failed to read directory: %v", b.err)).Layout(gtx)
+				}
+				if len(b.subdirs) == 0 {
+					return material.Body2(theme, "No subdirectories").Layout(gtx)
+				}
+				return layout.Dimensions{}
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return material.List(theme, &b.list).Layout(gtx, len(b.subdirs), func(gtx layout.Context, i int) layout.Dimensions {
+					return material.Clickable(gtx, &b.subdirButtons[i], func(gtx layout.Context) layout.Dimensions {
+						return layout.UniformInset(unit.Dp(4)).Layout(gtx, material.Body1(theme, b.subdirs[i]+string(filepath.Separator)).Layout)
+					})
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Rigid(material.Button(theme, &b.selectButton, "Use this folder").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Button(theme, &b.cancelButton, "Cancel").Layout),
+				)
+			}),
+		)
+	})
+}
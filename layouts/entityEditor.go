@@ -0,0 +1,184 @@
+package layouts
+
+import (
+	"fmt"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"example.com/main/translator"
+)
+
+// saveEntryFunc writes the dictionary entry of an entity and returns the updated entity
+type saveEntryFunc func(entry translator.Entry, dictEntry map[string]interface{}) (translator.Entry, error)
+
+// entityEditor is the pane showing every string of an entity's source next
+// to an editor for its translation
+type entityEditor struct {
+	entry   translator.Entry
+	leaves  []translator.StringLeaf
+	editors []tagEditor
+	list    widget.List
+	save    saveEntryFunc
+	saved   []string // editor texts as last loaded or saved
+	hints   []string // text shown by empty editors: the fallback translation, else the source
+
+	reviewed      widget.Bool
+	savedReviewed bool
+
+	onEdit   func(field int, before, after string) // called when the user edits a string
+	onReview func(reviewed bool)                   // called when the user toggles the review mark
+
+	saveButton widget.Clickable
+	status     string
+}
+
+func newEntityEditor(entry translator.Entry, completer *translator.TagCompleter, save saveEntryFunc) *entityEditor {
+	e := &entityEditor{entry: entry, save: save}
+	e.list.Axis = layout.Vertical
+	e.leaves = translator.EntryLeaves(entry.Source)
+	e.editors = make([]tagEditor, len(e.leaves))
+
+	// Editors start with the text of the locale's own dictionary; strings only
+	// a fallback layer translates stay empty and show that translation as hint
+	var primary, merged map[string]interface{}
+	if entry.Primary != nil {
+		primary = map[string]interface{}{"name": entry.Primary["name"], "entries": entry.Primary["entries"]}
+	}
+	if entry.Dictionary != nil {
+		merged = map[string]interface{}{"name": entry.Dictionary["name"], "entries": entry.Dictionary["entries"]}
+	}
+	e.saved = make([]string, len(e.leaves))
+	e.hints = make([]string, len(e.leaves))
+	for i, leaf := range e.leaves {
+		text, _ := translator.LookupString(primary, leaf.Path)
+		e.hints[i] = leaf.Text
+		if fallback, _ := translator.LookupString(merged, leaf.Path); text == "" && fallback != "" {
+			e.hints[i] = fallback
+		}
+		e.editors[i].completer = completer
+		e.editors[i].SetText(text)
+		e.editors[i].onEdit = func(before, after string) {
+			if e.onEdit != nil {
+				e.onEdit(i, before, after)
+			}
+		}
+		e.saved[i] = text
+	}
+	// Stale entries keep their review mark until the translator saves them
+	reviewed, _ := entry.Primary[translator.ReviewedField].(string)
+	e.reviewed.Value = reviewed == string(translator.StatusReviewed)
+	e.savedReviewed = e.reviewed.Value
+	return e
+}
+
+// dirty reports whether an editor changed since the entry was loaded or saved
+func (e *entityEditor) dirty() bool {
+	if e.reviewed.Value != e.savedReviewed {
+		return true
+	}
+	for i := range e.editors {
+		if e.editors[i].Text() != e.saved[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// setField replaces the text of a string without recording an edit
+func (e *entityEditor) setField(field int, text string) {
+	e.editors[field].SetText(text)
+}
+
+// setReviewed sets the review mark without recording an edit
+func (e *entityEditor) setReviewed(reviewed bool) {
+	e.reviewed.Value = reviewed
+}
+
+// texts returns the text of every editor
+func (e *entityEditor) texts() []string {
+	translations := make([]string, len(e.editors))
+	for i := range e.editors {
+		translations[i] = e.editors[i].Text()
+	}
+	return translations
+}
+
+// dictEntry builds the dictionary entry the editors describe
+func (e *entityEditor) dictEntry() (map[string]interface{}, error) {
+	dictEntry, err := translator.DictionaryEntry(e.entry.Source, e.entry.Primary, e.texts())
+	if err != nil {
+		return nil, err
+	}
+	if e.reviewed.Value {
+		dictEntry[translator.ReviewedField] = string(translator.StatusReviewed)
+	} else {
+		delete(dictEntry, translator.ReviewedField)
+	}
+	return dictEntry, nil
+}
+
+// onSave builds the dictionary entry from the editors and writes it
+func (e *entityEditor) onSave() error {
+	translations := e.texts()
+	dictEntry, err := e.dictEntry()
+	if err == nil {
+		e.entry, err = e.save(e.entry, dictEntry)
+	}
+	if err != nil {
+		e.status = fmt.Sprintf("Failed to save: %v", err)
+		return err
+	}
+	e.saved = translations
+	e.savedReviewed = e.reviewed.Value
+	e.status = "Saved"
+	return nil
+}
+
+func (e *entityEditor) Layout(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	if e.saveButton.Clicked(gtx) {
+		e.onSave()
+	}
+	if e.reviewed.Update(gtx) && e.onReview != nil {
+		e.onReview(e.reviewed.Value)
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(material.H5(theme, e.entry.Key).Layout),
+						layout.Rigid(material.Caption(theme, e.entry.File).Layout),
+					)
+				}),
+				layout.Rigid(material.Body2(theme, e.status).Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(material.CheckBox(theme, &e.reviewed, "Reviewed").Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(material.Button(theme, &e.saveButton, "Save").Layout),
+			)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(theme, &e.list).Layout(gtx, len(e.leaves), func(gtx layout.Context, i int) layout.Dimensions {
+				leaf := e.leaves[i]
+				return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+							return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+								layout.Rigid(material.Caption(theme, leaf.PathString()).Layout),
+								layout.Rigid(material.Body1(theme, leaf.Text).Layout),
+							)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+						layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+							return e.editors[i].Layout(theme, gtx, e.hints[i])
+						}),
+					)
+				})
+			})
+		}),
+	)
+}
@@ -0,0 +1,121 @@
+package layouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gioui.org/app"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"example.com/main/project"
+	"example.com/main/translator"
+)
+
+// localeBuild is the outcome of translating the data files of one locale
+type localeBuild struct {
+	locale string
+	stats  translator.Stats
+}
+
+// exportBuild runs Translator.Translate for every locale of a project in the
+// background and tracks its progress
+type exportBuild struct {
+	cancel       context.CancelFunc
+	cancelButton widget.Clickable
+	done         chan error
+
+	mu       sync.Mutex
+	locale   int
+	locales  []project.LocaleSettings
+	progress translator.Progress
+	results  []localeBuild
+}
+
+// startExportBuild starts building the export of every project locale
+func startExportBuild(window *app.Window, p *project.Project) *exportBuild {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &exportBuild{cancel: cancel, done: make(chan error, 1), locales: p.LocaleSettings()}
+
+	go func() {
+		defer cancel()
+		for i, locale := range b.locales {
+			b.mu.Lock()
+			b.locale = i
+			b.progress = translator.Progress{}
+			b.mu.Unlock()
+
+			t := p.ForLocale(locale).NewTranslator()
+			t.SetProgress(func(progress translator.Progress) {
+				b.mu.Lock()
+				b.progress = progress
+				b.mu.Unlock()
+				window.Invalidate()
+			})
+			err := t.TranslateContext(ctx)
+			if err != nil {
+				b.done <- fmt.Errorf("locale %s: %w", locale.Code, err)
+				window.Invalidate()
+				return
+			}
+
+			b.mu.Lock()
+			b.results = append(b.results, localeBuild{locale: locale.Code, stats: t.Stats()})
+			b.mu.Unlock()
+		}
+		b.done <- nil
+		window.Invalidate()
+	}()
+	return b
+}
+
+// canceled reports whether err ended a build because the user canceled it
+func (b *exportBuild) canceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// summary describes the results of the finished locales
+func (b *exportBuild) summary() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := make([]string, len(b.results))
+	for i, result := range b.results {
+		s := result.stats
+		lines[i] = fmt.Sprintf("%s: %d files (%d cached), %d/%d entities translated (%.1f%%), %d warnings",
+			result.locale, s.Files, s.SkippedFiles, s.Translated, s.SourceEntities, s.Coverage(), s.Issues)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Layout draws the progress bar with the current locale and stage and a cancel button
+func (b *exportBuild) Layout(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	if b.cancelButton.Clicked(gtx) {
+		b.cancel()
+	}
+
+	b.mu.Lock()
+	locale := b.locales[b.locale].Code
+	fraction := (float32(b.locale) + b.progress.Fraction()) / float32(len(b.locales))
+	status := fmt.Sprintf("Building %s", locale)
+	if b.progress.Stage != "" {
+		status = fmt.Sprintf("Building %s: %s %d/%d files", locale, b.progress.Stage, b.progress.Done, b.progress.Total)
+	}
+	b.mu.Unlock()
+
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(material.Caption(theme, status).Layout),
+				layout.Rigid(material.ProgressBar(theme, fraction).Layout),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Rigid(material.Button(theme, &b.cancelButton, "Cancel").Layout),
+	)
+}
@@ -0,0 +1,102 @@
+package layouts
+
+import (
+	"time"
+)
+
+// maxHistory limits the number of edits the workspace can undo
+const maxHistory = 500
+
+// mergeWindow is how long typing in a field keeps extending the last edit,
+// so undo reverts words rather than single keystrokes
+const mergeWindow = time.Second
+
+// editCommand is an edit in the project workspace that can be undone and redone
+type editCommand interface {
+	// apply opens the edited entity and restores its state before or after the edit
+	apply(w *LayoutProject, undo bool)
+	// merge extends the command with the next one when they form a single edit
+	merge(next editCommand) bool
+}
+
+// fieldEdit changes the translation of one string of an entity
+type fieldEdit struct {
+	entry, field  int // index into the project entries and into the entity's strings
+	before, after string
+	at            time.Time // time of the last merged change
+}
+
+func (c *fieldEdit) apply(w *LayoutProject, undo bool) {
+	text := c.after
+	if undo {
+		text = c.before
+	}
+	w.openEntity(c.entry).setField(c.field, text)
+}
+
+func (c *fieldEdit) merge(next editCommand) bool {
+	edit, ok := next.(*fieldEdit)
+	if !ok || edit.entry != c.entry || edit.field != c.field || edit.before != c.after || edit.at.Sub(c.at) > mergeWindow {
+		return false
+	}
+	c.after, c.at = edit.after, edit.at
+	return true
+}
+
+// reviewEdit marks an entity as reviewed or takes the mark away
+type reviewEdit struct {
+	entry    int
+	reviewed bool
+}
+
+func (c *reviewEdit) apply(w *LayoutProject, undo bool) {
+	w.openEntity(c.entry).setReviewed(c.reviewed != undo)
+}
+
+func (c *reviewEdit) merge(next editCommand) bool {
+	return false
+}
+
+// editHistory is the undo and redo stack of the project workspace
+type editHistory struct {
+	done   []editCommand
+	undone []editCommand
+	sealed bool // the last edit was undone or redone and must not grow
+}
+
+// push records a new edit, dropping the edits that could be redone
+func (h *editHistory) push(c editCommand) {
+	h.undone = nil
+	if n := len(h.done); n > 0 && !h.sealed && h.done[n-1].merge(c) {
+		return
+	}
+	h.sealed = false
+	h.done = append(h.done, c)
+	if len(h.done) > maxHistory {
+		h.done = append(h.done[:0], h.done[1:]...)
+	}
+}
+
+// undo returns the last edit and moves it to the redo stack, or nil
+func (h *editHistory) undo() editCommand {
+	if len(h.done) == 0 {
+		return nil
+	}
+	c := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, c)
+	h.sealed = true
+	return c
+}
+
+// redo returns the last undone edit and moves it back to the undo stack, or nil
+func (h *editHistory) redo() editCommand {
+	if len(h.undone) == 0 {
+		return nil
+	}
+	c := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, c)
+	h.sealed = true
+	return c
+}
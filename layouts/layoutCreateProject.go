@@ -1,120 +1,168 @@
 package layouts
 
 import (
-	"os"
+	"fmt"
 	"path/filepath"
+	"strings"
 
-	"gioui.org/app"
 	"gioui.org/layout"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
-	"gioui.org/x/explorer"
+
+	"example.com/main/project"
 )
 
 type LayoutCreateProject struct {
-	dbPath           string
-	savePath         string
 	dbPathEdit       widget.Editor
 	savePathEdit     widget.Editor
 	selectDBButton   widget.Clickable
 	selectSaveButton widget.Clickable
 	createBtn        widget.Clickable
-	explorer         *explorer.Explorer
-	selectDBCh       chan string
-	selectSaveCh     chan string
-	window           *app.Window
+	extractCheck     widget.Bool
+	createErr        error
+	nav              *Navigator
+
+	// The editors are validated whenever their text changes
+	validated   bool
+	checkedDB   string
+	checkedSave string
+	dbErr       error
+	saveErr     error
+	categories  []string
+	projectPath string
 }
 
-func (w *LayoutCreateProject) Init(window *app.Window) {
-	w.window = window
-	w.explorer = explorer.NewExplorer(window)
-	w.selectDBCh = make(chan string, 1)
-	w.selectSaveCh = make(chan string, 1)
+func (w *LayoutCreateProject) Init(nav *Navigator) {
+	w.nav = nav
+	w.dbPathEdit.SingleLine = true
+	w.savePathEdit.SingleLine = true
+	w.validate()
 }
 
-func (w *LayoutCreateProject) FrameEventHandler(theme *material.Theme, gtx layout.Context) LayoutWindow {
-	var layoutResult LayoutWindow = w
-
-	select {
-	case path := <-w.selectDBCh:
-		w.dbPath = filepath.Dir(path)
-		w.dbPathEdit.SetText(w.dbPath)
-	case path := <-w.selectSaveCh:
-		if path != "" {
-			w.savePath = path
-			w.savePathEdit.SetText(w.savePath)
+// validate checks the editors whose text changed since the last frame
+func (w *LayoutCreateProject) validate() {
+	if text := strings.TrimSpace(w.dbPathEdit.Text()); text != w.checkedDB || !w.validated {
+		w.checkedDB = text
+		w.categories, w.dbErr = nil, fmt.Errorf("select the plutonium data directory")
+		if text != "" {
+			w.categories, w.dbErr = project.ValidateDataPath(text)
 		}
-	default:
 	}
 
-	layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+	if text := strings.TrimSpace(w.savePathEdit.Text()); text != w.checkedSave || !w.validated {
+		w.checkedSave = text
+		w.projectPath, w.saveErr = "", fmt.Errorf("select where to save the project")
+		if text != "" {
+			w.projectPath, w.saveErr = project.ProjectFilePath(text)
+			if w.saveErr == nil {
+				w.saveErr = project.ValidateProjectPath(w.projectPath)
+			}
+		}
+	}
+	w.validated = true
+}
+
+func (w *LayoutCreateProject) Layout(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	w.validate()
+
+	return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{
 			Axis:    layout.Vertical,
 			Spacing: layout.SpaceEvenly,
 		}.Layout(gtx,
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
-					layout.Flexed(0.7, func(gtx layout.Context) layout.Dimensions {
-						return material.Editor(theme, &w.dbPathEdit, "Select plutonium data directory (select any file in data directory)").Layout(gtx)
-					}),
-					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						btn := material.Button(theme, &w.selectDBButton, "...")
-						if w.selectDBButton.Clicked(gtx) {
-							w.onSelectDB()
-						}
-						return btn.Layout(gtx)
-					}),
-				)
+				return w.layoutPathRow(theme, gtx, &w.dbPathEdit, &w.selectDBButton, "Plutonium data directory", "Select the plutonium data directory")
 			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
-					layout.Flexed(0.7, func(gtx layout.Context) layout.Dimensions {
-						return material.Editor(theme, &w.savePathEdit, "Save new project to ...").Layout(gtx)
-					}),
-					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						btn := material.Button(theme, &w.selectSaveButton, "...")
-						if w.selectSaveButton.Clicked(gtx) {
-							w.onSelectSave()
-						}
-						return btn.Layout(gtx)
-					}),
-				)
+				if w.dbErr != nil {
+					return w.layoutProblem(theme, gtx, w.checkedDB, w.dbErr)
+				}
+				return material.Caption(theme, fmt.Sprintf("Found %s", strings.Join(w.categories, ", "))).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.layoutPathRow(theme, gtx, &w.savePathEdit, &w.selectSaveButton, "Save new project to ...", "Select the project folder")
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if w.saveErr != nil {
+					return w.layoutProblem(theme, gtx, w.checkedSave, w.saveErr)
+				}
+				return material.Caption(theme, fmt.Sprintf("Project file: %s", w.projectPath)).Layout(gtx)
+			}),
+			layout.Rigid(material.CheckBox(theme, &w.extractCheck, "Scaffold the dictionary from the data files (extract)").Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if w.createErr == nil {
+					return layout.Dimensions{}
+				}
+				label := material.Body1(theme, w.createErr.Error())
+				label.Color = errorColor
+				return label.Layout(gtx)
 			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if w.dbErr != nil || w.saveErr != nil {
+					gtx = gtx.Disabled()
+				}
 				btn := material.Button(theme, &w.createBtn, "Create Project")
 				if w.createBtn.Clicked(gtx) {
-					layoutProject := &LayoutProject{}
-					layoutProject.Init(w.window)
-					layoutProject.projectPath = w.savePath
-					layoutResult = layoutProject
+					projectPath, err := w.onCreate()
+					w.createErr = err
+					if err == nil {
+						// Going back from the new project returns to the start screen
+						w.nav.Replace(&LayoutProject{projectPath: projectPath})
+					}
 				}
 				return btn.Layout(gtx)
 			}),
 		)
 	})
-	return layoutResult
 }
 
-func (w *LayoutCreateProject) onSelectDB() {
-	go func() {
-		reader, err := w.explorer.ChooseFile()
-		if err == nil && reader != nil {
-			defer reader.Close()
-			if f, ok := reader.(*os.File); ok {
-				w.selectDBCh <- f.Name()
-			}
-		}
-	}()
+// layoutPathRow draws a path editor with a button opening the directory browser for it
+func (w *LayoutCreateProject) layoutPathRow(theme *material.Theme, gtx layout.Context, editor *widget.Editor, button *widget.Clickable, hint, browserTitle string) layout.Dimensions {
+	if button.Clicked(gtx) {
+		w.nav.Push(newDirectoryBrowser(browserTitle, strings.TrimSpace(editor.Text()), editor.SetText))
+	}
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+		layout.Flexed(0.7, material.Editor(theme, editor, hint).Layout),
+		layout.Rigid(material.Button(theme, button, "...").Layout),
+	)
 }
 
-func (w *LayoutCreateProject) onSelectSave() {
-	go func() {
-		reader, err := w.explorer.ChooseFile()
-		if err == nil && reader != nil {
-			defer reader.Close()
-			if f, ok := reader.(*os.File); ok {
-				w.selectSaveCh <- f.Name()
-			}
+// layoutProblem shows why a path is invalid; empty editors only keep Create disabled
+func (w *LayoutCreateProject) layoutProblem(theme *material.Theme, gtx layout.Context, text string, err error) layout.Dimensions {
+	if text == "" {
+		return layout.Dimensions{}
+	}
+	label := material.Caption(theme, err.Error())
+	label.Color = errorColor
+	return label.Layout(gtx)
+}
+
+// onCreate creates the project described by the form and returns the project file path
+func (w *LayoutCreateProject) onCreate() (string, error) {
+	w.validate()
+	if w.dbErr != nil {
+		return "", w.dbErr
+	}
+	if w.saveErr != nil {
+		return "", w.saveErr
+	}
+
+	dataPath, err := filepath.Abs(w.checkedDB)
+	if err != nil {
+		return "", err
+	}
+
+	p, err := project.Create(w.projectPath, dataPath)
+	if err != nil {
+		return "", err
+	}
+
+	if w.extractCheck.Value {
+		_, err = p.NewTranslator().Extract()
+		if err != nil {
+			return "", fmt.Errorf("project created, but extract failed: %w", err)
 		}
-	}()
+	}
+
+	return p.Path(), nil
 }
@@ -1,46 +1,84 @@
 package layouts
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
-	"gioui.org/app"
 	"gioui.org/layout"
+	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/explorer"
+
+	"example.com/main/project"
 )
 
 type LayoutMain struct {
-	createButton    widget.Clickable
-	openButton      widget.Clickable
-	explorer        *explorer.Explorer
-	window          *app.Window
-	openProjectChan chan string
+	createButton        widget.Clickable
+	openButton          widget.Clickable
+	removeMissingButton widget.Clickable
+	explorer            *explorer.Explorer
+	nav                 *Navigator
+	openProjectChan     chan string
+
+	recent        *project.RecentProjects
+	recentErr     error
+	recentOpen    []widget.Clickable
+	recentRemove  []widget.Clickable
+	recentMissing []bool
+	recentList    widget.List
 }
 
-func (w *LayoutMain) Init(window *app.Window) {
-	w.window = window
-	w.explorer = explorer.NewExplorer(window)
-	w.createButton = widget.Clickable{}
-	w.openButton = widget.Clickable{}
+func (w *LayoutMain) Init(nav *Navigator) {
+	w.nav = nav
+	w.explorer = explorer.NewExplorer(nav.Window())
 	w.openProjectChan = make(chan string, 1)
+	w.recentList.Axis = layout.Vertical
 }
 
-func (w *LayoutMain) FrameEventHandler(theme *material.Theme, gtx layout.Context) LayoutWindow {
-	var layoutResult LayoutWindow = w
+// OnEnter reloads the recent projects, which change while a project is open
+func (w *LayoutMain) OnEnter() {
+	w.loadRecent()
+}
 
+// loadRecent reads the recent projects list and checks which projects still exist
+func (w *LayoutMain) loadRecent() {
+	w.recent, w.recentErr = loadRecent()
+	if w.recent == nil {
+		w.recent = &project.RecentProjects{}
+	}
+	w.recentOpen = make([]widget.Clickable, len(w.recent.Projects))
+	w.recentRemove = make([]widget.Clickable, len(w.recent.Projects))
+	w.recentMissing = make([]bool, len(w.recent.Projects))
+	for i, recent := range w.recent.Projects {
+		w.recentMissing[i] = !recent.Exists()
+	}
+}
+
+// saveRecent writes the recent projects list after a change and reloads it
+func (w *LayoutMain) saveRecent() {
+	w.recentErr = w.recent.Save()
+	if w.recentErr == nil {
+		w.loadRecent()
+	}
+}
+
+// openProject shows the workspace of a project
+func (w *LayoutMain) openProject(path string) {
+	w.nav.Push(&LayoutProject{projectPath: path})
+}
+
+func (w *LayoutMain) Layout(theme *material.Theme, gtx layout.Context) layout.Dimensions {
 	select {
 	case path := <-w.openProjectChan:
 		if path != "" {
-			layoutProject := &LayoutProject{}
-			layoutProject.Init(w.window)
-			layoutProject.projectPath = path
-			return layoutProject
+			w.openProject(path)
 		}
 	default:
 	}
 
-	layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+	return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{
 			Axis:    layout.Vertical,
 			Spacing: layout.SpaceEvenly,
@@ -48,9 +86,7 @@ func (w *LayoutMain) FrameEventHandler(theme *material.Theme, gtx layout.Context
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				btn := material.Button(theme, &w.createButton, "Create New Project")
 				if w.createButton.Clicked(gtx) {
-					layoutCreate := &LayoutCreateProject{}
-					layoutCreate.Init(w.window)
-					layoutResult = layoutCreate
+					w.nav.Push(&LayoutCreateProject{})
 				}
 				return btn.Layout(gtx)
 			}),
@@ -61,19 +97,106 @@ func (w *LayoutMain) FrameEventHandler(theme *material.Theme, gtx layout.Context
 				}
 				return btn.Layout(gtx)
 			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.layoutRecent(theme, gtx)
+			}),
 		)
 	})
-	return layoutResult
+}
+
+// layoutRecent draws the recent projects list and opens a project clicked in it
+func (w *LayoutMain) layoutRecent(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	for i := range w.recent.Projects {
+		if w.recentOpen[i].Clicked(gtx) {
+			if w.recentMissing[i] {
+				w.nav.NotifyError(fmt.Sprintf("%s no longer exists", w.recent.Projects[i].Path))
+			} else {
+				w.openProject(w.recent.Projects[i].Path)
+			}
+		}
+		if w.recentRemove[i].Clicked(gtx) {
+			recent := w.recent.Projects[i]
+			w.nav.Confirm("Remove recent project", fmt.Sprintf("Remove %s from the recent projects? The project files are kept.", recent.Name), "Remove", func() {
+				w.recent.Remove(recent.Path)
+				w.saveRecent()
+			})
+		}
+	}
+	if w.removeMissingButton.Clicked(gtx) {
+		w.recent.RemoveMissing()
+		w.saveRecent()
+	}
+
+	gtx.Constraints.Max.Y = gtx.Dp(unit.Dp(360))
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+		layout.Rigid(material.H6(theme, "Recent projects").Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if w.recentErr != nil {
+				return material.Body2(theme, fmt.Sprintf("Failed to read recent projects: %v", w.recentErr)).Layout(gtx)
+			}
+			if len(w.recent.Projects) == 0 {
+				return material.Body2(theme, "No recent projects").Layout(gtx)
+			}
+			return layout.Dimensions{}
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(theme, &w.recentList).Layout(gtx, len(w.recent.Projects), func(gtx layout.Context, i int) layout.Dimensions {
+				return w.layoutRecentProject(theme, gtx, i)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			for _, missing := range w.recentMissing {
+				if missing {
+					return material.Button(theme, &w.removeMissingButton, "Remove missing projects").Layout(gtx)
+				}
+			}
+			return layout.Dimensions{}
+		}),
+	)
+}
+
+// layoutRecentProject draws one entry of the recent projects list
+func (w *LayoutMain) layoutRecentProject(theme *material.Theme, gtx layout.Context, i int) layout.Dimensions {
+	recent := w.recent.Projects[i]
+	details := fmt.Sprintf("%s · %s · opened %s · %.1f%% translated", recent.Path, recent.Locale, recent.LastOpened.Format("2006-01-02 15:04"), recent.Coverage)
+	if w.recentMissing[i] {
+		details = "Missing: " + recent.Path
+	}
+
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, &w.recentOpen[i], func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(material.Body1(theme, recent.Name).Layout),
+						layout.Rigid(material.Caption(theme, details).Layout),
+					)
+				})
+			})
+		}),
+		layout.Rigid(material.Button(theme, &w.recentRemove[i], "Remove").Layout),
+	)
 }
 
 func (w *LayoutMain) onOpen() {
 	go func() {
 		reader, err := w.explorer.ChooseFile(".langproj")
-		if err == nil && reader != nil {
-			defer reader.Close()
-			if f, ok := reader.(*os.File); ok {
-				w.openProjectChan <- f.Name()
-			}
+		if errors.Is(err, explorer.ErrUserDecline) || err == nil && reader == nil {
+			return
+		}
+		if err != nil {
+			w.nav.ShowError("Could not open project", err)
+			return
+		}
+		defer reader.Close()
+
+		f, ok := reader.(*os.File)
+		if !ok {
+			w.nav.ShowError("Could not open project", errors.New("the selected file has no local path"))
+			return
 		}
+		w.openProjectChan <- f.Name()
+		w.nav.Window().Invalidate()
 	}()
 }
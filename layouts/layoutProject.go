@@ -1,24 +1,532 @@
 package layouts
 
 import (
-	"gioui.org/app"
+	"fmt"
+	"log"
+	"time"
+
 	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
-	"fmt"
+
+	"example.com/main/project"
+	"example.com/main/translator"
 )
 
+// autosaveDelay is how long the workspace waits after the last edit before
+// it saves the open entity
+const autosaveDelay = 2 * time.Second
+
+// draftDelay is how long the workspace waits after the last edit before it
+// writes the draft of the open entity to the recovery file
+const draftDelay = 500 * time.Millisecond
+
+// entriesResult is the outcome of loading the entities of a project in the background
+type entriesResult struct {
+	entries []translator.Entry
+	err     error
+}
+
 type LayoutProject struct {
-	window *app.Window
+	nav         *Navigator
 	projectPath string
+	project     *project.Project
+	loadErr     error
+
+	entriesCh  chan entriesResult
+	loading    bool
+	entries    []translator.Entry
+	translator *translator.Translator
+
+	category        string // selected category, empty for all of them
+	allButton       widget.Clickable
+	categoryButtons []widget.Clickable
+	search          *translator.EntrySearch
+	searchBar       *searchBar
+	completer       *translator.TagCompleter
+	filter          translator.EntryFilter
+	visible         []int // indexes of the entries matching the filter
+	entityButtons   []widget.Clickable
+	entityList      widget.List
+	editor          *entityEditor
+	editorIndex     int // index of the edited entity in entries
+
+	history    editHistory
+	undoButton widget.Clickable
+	redoButton widget.Clickable
+	recovery   *project.Recovery
+	lastEdit   time.Time // time of the last edit not saved yet, zero when saved
+	draftEdit  time.Time // time of the last edit not in the recovery file yet, zero when written
+
+	buildButton widget.Clickable
+	build       *exportBuild
+}
+
+func (w *LayoutProject) Layout(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	if w.project == nil && w.loadErr == nil {
+		w.project, w.loadErr = project.Load(w.projectPath)
+		if w.loadErr == nil {
+			updateRecent(func(recent *project.RecentProjects) {
+				recent.Touch(w.project, time.Now())
+			})
+			w.loadEntries()
+		}
+	}
+
+	select {
+	case result := <-w.entriesCh:
+		w.loading = false
+		w.loadErr = result.err
+		w.entries = result.entries
+		w.categoryButtons = make([]widget.Clickable, len(w.project.Categories))
+		if result.err == nil {
+			w.recordCoverage()
+			w.search = translator.NewEntrySearch(w.entries)
+			w.searchBar = newSearchBar(w.entries)
+			w.completer = translator.NewTagCompleter(w.entries)
+		}
+		if w.category == "" && len(w.project.Categories) > 0 {
+			w.selectCategory(w.project.Categories[0])
+		}
+		if result.err == nil {
+			w.loadRecovery()
+		}
+	default:
+	}
+
+	if w.build != nil {
+		select {
+		case err := <-w.build.done:
+			w.finishBuild(err)
+		default:
+		}
+	}
+
+	if w.loadErr != nil {
+		return layout.Center.Layout(gtx, material.Body1(theme, fmt.Sprintf("Failed to open project: %v", w.loadErr)).Layout)
+	}
+	if w.loading {
+		return layout.Center.Layout(gtx, material.Body1(theme, fmt.Sprintf("Loading %s...", w.projectPath)).Layout)
+	}
+
+	if !w.draftEdit.IsZero() {
+		if due := w.draftEdit.Add(draftDelay); gtx.Now.Before(due) {
+			gtx.Execute(op.InvalidateCmd{At: due})
+		} else {
+			w.saveDraft()
+		}
+	}
+	if !w.lastEdit.IsZero() {
+		if due := w.lastEdit.Add(autosaveDelay); gtx.Now.Before(due) {
+			gtx.Execute(op.InvalidateCmd{At: due})
+		} else {
+			w.autosave()
+		}
+	}
+
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.layoutHeader(theme, gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				dims := w.searchBar.Layout(theme, gtx)
+				w.applyFilter(false)
+				return dims
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return w.layoutWorkspace(theme, gtx)
+			}),
+		)
+	})
+}
+
+// layoutHeader draws the project name, the undo and redo actions and the
+// export build action or its progress
+func (w *LayoutProject) layoutHeader(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	if w.buildButton.Clicked(gtx) && w.build == nil {
+		w.build = startExportBuild(w.nav.Window(), w.project)
+	}
+	if w.undoButton.Clicked(gtx) {
+		if c := w.history.undo(); c != nil {
+			c.apply(w, true)
+			w.changed()
+		}
+	}
+	if w.redoButton.Clicked(gtx) {
+		if c := w.history.redo(); c != nil {
+			c.apply(w, false)
+			w.changed()
+		}
+	}
+
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(material.H6(theme, w.project.Name).Layout),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			if w.build == nil {
+				return layout.Dimensions{Size: gtx.Constraints.Min}
+			}
+			return w.build.Layout(theme, gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if len(w.history.done) == 0 {
+				gtx = gtx.Disabled()
+			}
+			return material.Button(theme, &w.undoButton, "Undo").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if len(w.history.undone) == 0 {
+				gtx = gtx.Disabled()
+			}
+			return material.Button(theme, &w.redoButton, "Redo").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if w.build != nil {
+				gtx = gtx.Disabled()
+			}
+			return material.Button(theme, &w.buildButton, "Build export").Layout(gtx)
+		}),
+	)
+}
+
+// finishBuild reports the outcome of the export build
+func (w *LayoutProject) finishBuild(err error) {
+	switch {
+	case err == nil:
+		w.nav.ShowMessage("Export built", w.build.summary()+"\n\nWritten to "+w.project.Resolve(w.project.ExportPath))
+	case w.build.canceled(err):
+		w.nav.Notify("Export build canceled")
+	default:
+		w.nav.ShowError("Export build failed", err)
+	}
+	w.build = nil
+}
+
+// layoutWorkspace draws the categories, the entities of the selected category and the editor
+func (w *LayoutProject) layoutWorkspace(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return w.layoutCategories(theme, gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Flexed(0.3, func(gtx layout.Context) layout.Dimensions {
+			return w.layoutEntities(theme, gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Flexed(0.7, func(gtx layout.Context) layout.Dimensions {
+			if w.editor == nil {
+				return layout.Center.Layout(gtx, material.Body1(theme, "Select an entity to translate").Layout)
+			}
+			return w.editor.Layout(theme, gtx)
+		}),
+	)
+}
+
+func (w *LayoutProject) Init(nav *Navigator) {
+	w.nav = nav
+	w.entriesCh = make(chan entriesResult, 1)
+	w.entityList.Axis = layout.Vertical
+}
+
+// OnLeave saves the edits of the open entity so leaving the workspace keeps them
+func (w *LayoutProject) OnLeave() {
+	if w.editor == nil || !w.editor.dirty() {
+		return
+	}
+	if w.autosave() {
+		w.nav.Notify(fmt.Sprintf("Saved %s", w.editor.entry.Key))
+	}
+}
+
+// OnClose stops a running export build; edits that could not be saved stay
+// in the recovery file
+func (w *LayoutProject) OnClose() {
+	w.saveDraft()
+	if w.build != nil {
+		w.build.cancel()
+	}
+}
+
+// loadEntries reads the entities of the first project locale in the background
+func (w *LayoutProject) loadEntries() {
+	w.loading = true
+	w.translator = w.project.ForLocale(w.project.LocaleSettings()[0]).NewTranslator()
+	go func() {
+		entries, err := w.translator.Entries()
+		w.entriesCh <- entriesResult{entries: entries, err: err}
+		w.nav.Window().Invalidate()
+	}()
 }
 
-func (w *LayoutProject) FrameEventHandler(theme *material.Theme, gtx layout.Context) LayoutWindow {
-	layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		return material.H4(theme, fmt.Sprintf("Project opened: %s", w.projectPath)).Layout(gtx)
+// selectCategory lists the matching entities of a category, or of all of
+// them when category is empty, and closes the editor
+func (w *LayoutProject) selectCategory(category string) {
+	w.autosave()
+	w.category = category
+	w.editor = nil
+	w.applyFilter(true)
+}
+
+// applyFilter searches the entities again when the search bar or the
+// selected category changed, or always when forced
+func (w *LayoutProject) applyFilter(force bool) {
+	if w.search == nil {
+		return
+	}
+	filter := w.searchBar.filter(w.category)
+	if filter == w.filter && !force {
+		return
+	}
+	if filter != w.filter {
+		w.entityList.Position = layout.Position{}
+	}
+	w.filter = filter
+	w.visible = w.search.Search(filter)
+	if len(w.entityButtons) != len(w.visible) {
+		w.entityButtons = make([]widget.Clickable, len(w.visible))
+	}
+}
+
+// layoutCategories draws a button per project category with its translation
+// progress, after one for all categories
+func (w *LayoutProject) layoutCategories(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if w.allButton.Clicked(gtx) {
+				w.selectCategory("")
+			}
+			return w.layoutCategoryButton(theme, gtx, &w.allButton, "")
+		}),
+	}
+	for i, category := range w.project.Categories {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if w.categoryButtons[i].Clicked(gtx) {
+				w.selectCategory(category)
+			}
+			return w.layoutCategoryButton(theme, gtx, &w.categoryButtons[i], category)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+// layoutCategoryButton draws the button of a category, or of all categories when category is empty
+func (w *LayoutProject) layoutCategoryButton(theme *material.Theme, gtx layout.Context, button *widget.Clickable, category string) layout.Dimensions {
+	translated, total := 0, 0
+	for _, entry := range w.entries {
+		if category == "" || entry.Category == category {
+			total++
+			if entry.Status().Translated() {
+				translated++
+			}
+		}
+	}
+
+	label := category
+	if category == "" {
+		label = "all"
+	}
+	btn := material.Button(theme, button, fmt.Sprintf("%s %d/%d", label, translated, total))
+	if category != w.category {
+		btn.Background = theme.Palette.ContrastFg
+		btn.Color = theme.Palette.Fg
+	}
+	return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, btn.Layout)
+}
+
+// layoutEntities draws the entities matching the filter with their status
+func (w *LayoutProject) layoutEntities(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	return material.List(theme, &w.entityList).Layout(gtx, len(w.visible), func(gtx layout.Context, i int) layout.Dimensions {
+		index := w.visible[i]
+		entry := w.entries[index]
+		if w.entityButtons[i].Clicked(gtx) {
+			w.openEntity(index)
+		}
+
+		label := "○ " + entry.Key
+		if entry.Translated != nil {
+			name, _ := entry.Translated["name"].(string)
+			label = "● " + name + " — " + entry.Key
+		}
+		if status := entry.Status(); status == translator.StatusInherited || status == translator.StatusReviewed || status == translator.StatusStale {
+			label += " · " + string(status)
+		}
+		if len(entry.Issues) > 0 {
+			label += fmt.Sprintf(" · %d warning(s)", len(entry.Issues))
+		}
+		return material.Clickable(gtx, &w.entityButtons[i], func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(4)).Layout(gtx, material.Body1(theme, label).Layout)
+		})
+	})
+}
+
+// openEntity shows the editor of the entity at index, saving the edits of
+// the previous one first, and returns it
+func (w *LayoutProject) openEntity(index int) *entityEditor {
+	if w.editor != nil && w.editorIndex == index {
+		return w.editor
+	}
+	w.autosave()
+
+	w.editorIndex = index
+	w.editor = newEntityEditor(w.entries[index], w.completer, func(entry translator.Entry, dictEntry map[string]interface{}) (translator.Entry, error) {
+		return w.saveEntry(index, entry, dictEntry)
 	})
-	return w
+	w.editor.onEdit = func(field int, before, after string) {
+		w.history.push(&fieldEdit{entry: index, field: field, before: before, after: after, at: time.Now()})
+		w.changed()
+	}
+	w.editor.onReview = func(reviewed bool) {
+		w.history.push(&reviewEdit{entry: index, reviewed: reviewed})
+		w.changed()
+	}
+	return w.editor
 }
 
-func (w *LayoutProject) Init(window *app.Window) {
-	w.window = window
-}
\ No newline at end of file
+// changed schedules writing the draft of the open entity to the recovery
+// file and its autosave
+func (w *LayoutProject) changed() {
+	w.draftEdit = time.Now()
+	if w.editor.dirty() {
+		w.lastEdit = w.draftEdit
+	} else {
+		w.lastEdit = time.Time{}
+	}
+}
+
+// saveDraft keeps the unsaved edits of the open entity in the recovery file,
+// or removes its draft once there are none
+func (w *LayoutProject) saveDraft() {
+	if w.draftEdit.IsZero() || w.editor == nil {
+		return
+	}
+	w.draftEdit = time.Time{}
+
+	entry := w.editor.entry
+	if !w.editor.dirty() {
+		w.recovery.Remove(entry.Category, entry.Key)
+		w.saveRecovery()
+		return
+	}
+	dictEntry, err := w.editor.dictEntry()
+	if err != nil {
+		log.Printf("Failed to build the draft of %s: %v", entry.Key, err)
+		return
+	}
+	w.recovery.Set(entry.Category, entry.Key, dictEntry, w.lastEdit)
+	w.saveRecovery()
+}
+
+// autosave saves the open entity when it has unsaved edits and reports
+// whether it has none left
+func (w *LayoutProject) autosave() bool {
+	if w.editor == nil || !w.editor.dirty() {
+		w.saveDraft()
+		w.lastEdit = time.Time{}
+		return true
+	}
+	if err := w.editor.onSave(); err != nil {
+		w.nav.NotifyError(fmt.Sprintf("Failed to save %s: %v", w.editor.entry.Key, err))
+		w.saveDraft()
+		w.lastEdit = time.Time{}
+		return false
+	}
+	w.draftEdit, w.lastEdit = time.Time{}, time.Time{}
+	return true
+}
+
+// saveEntry writes an edited dictionary entry and updates the listed entity
+func (w *LayoutProject) saveEntry(index int, entry translator.Entry, dictEntry map[string]interface{}) (translator.Entry, error) {
+	_, err := w.translator.SaveEntry(entry, dictEntry)
+	if err != nil {
+		return entry, err
+	}
+
+	entry, err = w.translator.MergeEntry(entry, dictEntry)
+	if err != nil {
+		return entry, err
+	}
+	w.search.Update(index, entry)
+	w.applyFilter(true)
+
+	w.recovery.Remove(entry.Category, entry.Key)
+	w.saveRecovery()
+	return entry, nil
+}
+
+// loadRecovery reads the edits left unsaved by a crash and offers to recover them
+func (w *LayoutProject) loadRecovery() {
+	recovery, err := project.LoadRecovery(w.project.RecoveryPath())
+	w.recovery = recovery
+	if err != nil {
+		w.nav.NotifyError(fmt.Sprintf("Failed to read unsaved changes: %v", err))
+		return
+	}
+	if len(recovery.Drafts) == 0 {
+		return
+	}
+
+	message := fmt.Sprintf("%d entities have changes from %s that were not saved. Recover them?",
+		len(recovery.Drafts), recovery.Updated.Local().Format("2006-01-02 15:04"))
+	w.nav.Choose("Recover unsaved changes", message, "Recover", "Discard", w.recoverDrafts, func() {
+		w.recovery.Drafts = nil
+		w.saveRecovery()
+	})
+}
+
+// recoverDrafts saves the drafts of the recovery file to the dictionary
+func (w *LayoutProject) recoverDrafts() {
+	drafts := append([]project.RecoveryDraft(nil), w.recovery.Drafts...)
+	recovered := 0
+	for _, draft := range drafts {
+		index := -1
+		for i, entry := range w.entries {
+			if entry.Category == draft.Category && entry.Key == draft.Key {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			// The entity is gone from the source data, so the draft can't be saved
+			w.nav.NotifyError(fmt.Sprintf("Failed to recover %s: the entity no longer exists", draft.Key))
+			w.recovery.Remove(draft.Category, draft.Key)
+			w.saveRecovery()
+			continue
+		}
+		if _, err := w.saveEntry(index, w.entries[index], draft.Entry); err != nil {
+			w.nav.NotifyError(fmt.Sprintf("Failed to recover %s: %v", draft.Key, err))
+			continue
+		}
+		recovered++
+	}
+	w.nav.Notify(fmt.Sprintf("Recovered %d of %d entities", recovered, len(drafts)))
+}
+
+// saveRecovery writes the recovery file; it only guards against crashes, so
+// failures are only logged
+func (w *LayoutProject) saveRecovery() {
+	if err := w.recovery.Save(); err != nil {
+		log.Printf("Failed to save unsaved changes: %v", err)
+	}
+}
+
+// recordCoverage stores the share of translated entities in the recent projects list
+func (w *LayoutProject) recordCoverage() {
+	translated := 0
+	for _, entry := range w.entries {
+		if entry.Status().Translated() {
+			translated++
+		}
+	}
+	stats := translator.Stats{SourceEntities: len(w.entries), Translated: translated}
+	updateRecent(func(recent *project.RecentProjects) {
+		recent.SetCoverage(w.project.Path(), stats.Coverage())
+	})
+}
@@ -3,10 +3,28 @@ package layouts
 import (
 	"gioui.org/layout"
 	"gioui.org/widget/material"
-	"gioui.org/app"
 )
 
+// LayoutWindow is a screen shown by the Navigator
 type LayoutWindow interface {
-	Init(window *app.Window)
-	FrameEventHandler(theme *material.Theme, gtx layout.Context) LayoutWindow
+	// Init is called once when the screen is pushed onto the navigator
+	Init(nav *Navigator)
+	Layout(theme *material.Theme, gtx layout.Context) layout.Dimensions
+}
+
+// EnterHook is implemented by screens that act whenever they become the visible screen
+type EnterHook interface {
+	OnEnter()
+}
+
+// LeaveHook is implemented by screens that act whenever another screen covers
+// them or they are popped, for example to save pending changes
+type LeaveHook interface {
+	OnLeave()
+}
+
+// CloseHook is implemented by screens that release resources when they are
+// removed from the navigator, after OnLeave
+type CloseHook interface {
+	OnClose()
 }
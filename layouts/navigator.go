@@ -0,0 +1,184 @@
+package layouts
+
+import (
+	"gioui.org/app"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Navigator keeps the stack of screens of a window, shows the top one with a
+// back action and draws notifications and modal dialogs over it
+type Navigator struct {
+	window        *app.Window
+	stack         []LayoutWindow
+	backButton    widget.Clickable
+	notifications notifier
+}
+
+// NewNavigator creates a navigator for window, starting at root
+func NewNavigator(window *app.Window, root LayoutWindow) *Navigator {
+	n := &Navigator{window: window}
+	n.Push(root)
+	return n
+}
+
+// Window returns the window the navigator draws into
+func (n *Navigator) Window() *app.Window {
+	return n.window
+}
+
+// Push shows screen on top of the current one
+func (n *Navigator) Push(screen LayoutWindow) {
+	if top := n.top(); top != nil {
+		leave(top)
+	}
+	n.push(screen)
+}
+
+// Pop closes the current screen and returns to the previous one; the root
+// screen is never popped
+func (n *Navigator) Pop() {
+	if !n.CanPop() {
+		return
+	}
+	n.remove()
+	enter(n.top())
+	n.window.Invalidate()
+}
+
+// Replace closes the current screen and shows screen in its place, so going
+// back skips the replaced screen
+func (n *Navigator) Replace(screen LayoutWindow) {
+	if len(n.stack) > 0 {
+		n.remove()
+	}
+	n.push(screen)
+}
+
+// CanPop reports whether there is a screen to go back to
+func (n *Navigator) CanPop() bool {
+	return len(n.stack) > 1
+}
+
+// Close runs the leave and close hooks of every screen, top first, when the window closes
+func (n *Navigator) Close() {
+	for len(n.stack) > 0 {
+		n.remove()
+	}
+}
+
+// Notify shows a toast; it may be called from any goroutine
+func (n *Navigator) Notify(message string) {
+	n.notifications.add(&toast{message: message})
+	n.window.Invalidate()
+}
+
+// NotifyError shows a toast styled as an error; it may be called from any goroutine
+func (n *Navigator) NotifyError(message string) {
+	n.notifications.add(&toast{message: message, isError: true})
+	n.window.Invalidate()
+}
+
+// ShowError opens a modal error dialog; it may be called from any goroutine
+func (n *Navigator) ShowError(title string, err error) {
+	n.notifications.open(&dialog{title: title, message: err.Error(), isError: true})
+	n.window.Invalidate()
+}
+
+// ShowMessage opens a modal dialog with a message; it may be called from any goroutine
+func (n *Navigator) ShowMessage(title, message string) {
+	n.notifications.open(&dialog{title: title, message: message})
+	n.window.Invalidate()
+}
+
+// Confirm opens a modal dialog asking the user to confirm an action; onConfirm
+// runs on the UI goroutine if they do
+func (n *Navigator) Confirm(title, message, confirmLabel string, onConfirm func()) {
+	n.Choose(title, message, confirmLabel, "", onConfirm, nil)
+}
+
+// Choose opens a modal dialog with two actions; the callback of the chosen
+// one runs on the UI goroutine. An empty cancelLabel shows "Cancel".
+func (n *Navigator) Choose(title, message, confirmLabel, cancelLabel string, onConfirm, onCancel func()) {
+	n.notifications.open(&dialog{
+		title: title, message: message,
+		confirmLabel: confirmLabel, onConfirm: onConfirm,
+		cancelLabel: cancelLabel, onCancel: onCancel,
+	})
+	n.window.Invalidate()
+}
+
+// Layout draws the current screen, with a back bar when there is a screen to
+// return to, and the notifications over it
+func (n *Navigator) Layout(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	// Dialogs block input to the screen below them
+	screenGtx := gtx
+	if n.notifications.modal() {
+		screenGtx = gtx.Disabled()
+	}
+	if n.backButton.Clicked(screenGtx) {
+		n.Pop()
+	}
+
+	top := n.top()
+	dims := layout.Flex{Axis: layout.Vertical}.Layout(screenGtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !n.CanPop() {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: unit.Dp(8), Top: unit.Dp(8)}.Layout(gtx, material.Button(theme, &n.backButton, "Back").Layout)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return top.Layout(theme, gtx)
+		}),
+	)
+	n.notifications.layout(theme, gtx)
+
+	// Screens changed during this frame are drawn on the next one
+	if n.top() != top {
+		n.window.Invalidate()
+	}
+	return dims
+}
+
+// top returns the visible screen
+func (n *Navigator) top() LayoutWindow {
+	if len(n.stack) == 0 {
+		return nil
+	}
+	return n.stack[len(n.stack)-1]
+}
+
+// push initializes and shows a screen without touching the one below
+func (n *Navigator) push(screen LayoutWindow) {
+	n.stack = append(n.stack, screen)
+	screen.Init(n)
+	enter(screen)
+	n.window.Invalidate()
+}
+
+// remove takes the top screen off the stack and runs its hooks
+func (n *Navigator) remove() {
+	screen := n.top()
+	n.stack = n.stack[:len(n.stack)-1]
+	leave(screen)
+	if hook, ok := screen.(CloseHook); ok {
+		hook.OnClose()
+	}
+}
+
+// enter runs the enter hook of a screen
+func enter(screen LayoutWindow) {
+	if hook, ok := screen.(EnterHook); ok {
+		hook.OnEnter()
+	}
+}
+
+// leave runs the leave hook of a screen
+func leave(screen LayoutWindow) {
+	if hook, ok := screen.(LeaveHook); ok {
+		hook.OnLeave()
+	}
+}
@@ -0,0 +1,214 @@
+package layouts
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// toastDuration is how long a toast stays on screen
+const toastDuration = 4 * time.Second
+
+// toast is a short message shown at the bottom of the window until it expires or is clicked
+type toast struct {
+	message string
+	isError bool
+	expires time.Time
+	dismiss widget.Clickable
+}
+
+// dialog is a modal message the user has to acknowledge, or a question with
+// a confirm action when onConfirm is set
+type dialog struct {
+	title        string
+	message      string
+	isError      bool
+	confirmLabel string
+	onConfirm    func()
+	cancelLabel  string
+	onCancel     func() // optional
+
+	okButton     widget.Clickable
+	cancelButton widget.Clickable
+}
+
+// notifier holds the toasts and dialogs the navigator draws over every
+// screen; messages may be added from any goroutine
+type notifier struct {
+	mu      sync.Mutex
+	toasts  []*toast
+	dialogs []*dialog
+}
+
+// errorColor is the text color of errors
+var errorColor = color.NRGBA{R: 0xb0, A: 0xff}
+
+// add shows a toast
+func (n *notifier) add(t *toast) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.toasts = append(n.toasts, t)
+}
+
+// open queues a dialog behind the ones already open
+func (n *notifier) open(d *dialog) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.dialogs = append(n.dialogs, d)
+}
+
+// modal reports whether a dialog is open, in which case the screen below
+// must be laid out disabled
+func (n *notifier) modal() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.dialogs) > 0
+}
+
+// layout draws the toasts and the oldest open dialog over the current screen
+func (n *notifier) layout(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	n.mu.Lock()
+
+	// Toasts start their timer on the first frame that shows them
+	toasts := n.toasts[:0]
+	var next time.Time
+	for _, t := range n.toasts {
+		if t.expires.IsZero() {
+			t.expires = gtx.Now.Add(toastDuration)
+		}
+		if t.dismiss.Clicked(gtx) || !gtx.Now.Before(t.expires) {
+			continue
+		}
+		toasts = append(toasts, t)
+		if next.IsZero() || t.expires.Before(next) {
+			next = t.expires
+		}
+	}
+	n.toasts = toasts
+	if !next.IsZero() {
+		gtx.Execute(op.InvalidateCmd{At: next})
+	}
+
+	// The dialog actions run unlocked since they may open another dialog
+	var action func()
+	if len(n.dialogs) > 0 {
+		d := n.dialogs[0]
+		if d.okButton.Clicked(gtx) {
+			action = d.onConfirm
+			n.dialogs = n.dialogs[1:]
+		} else if d.cancelButton.Clicked(gtx) {
+			action = d.onCancel
+			n.dialogs = n.dialogs[1:]
+		}
+	}
+
+	toasts = append([]*toast(nil), n.toasts...)
+	var open *dialog
+	if len(n.dialogs) > 0 {
+		open = n.dialogs[0]
+	}
+	n.mu.Unlock()
+
+	if action != nil {
+		action()
+	}
+
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			return layout.S.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				children := make([]layout.FlexChild, len(toasts))
+				for i, t := range toasts {
+					children[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return layoutToast(theme, gtx, t)
+						})
+					})
+				}
+				return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+			})
+		}),
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			if open == nil {
+				return layout.Dimensions{}
+			}
+			return layoutDialog(theme, gtx, open)
+		}),
+	)
+}
+
+// layoutToast draws a single toast; clicking it dismisses it
+func layoutToast(theme *material.Theme, gtx layout.Context, t *toast) layout.Dimensions {
+	background := color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xe0}
+	if t.isError {
+		background = color.NRGBA{R: 0xb0, A: 0xe0}
+	}
+	return material.Clickable(gtx, &t.dismiss, func(gtx layout.Context) layout.Dimensions {
+		return layout.Background{}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return fillRounded(gtx, background)
+		}, func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(theme, t.message)
+				label.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+				return label.Layout(gtx)
+			})
+		})
+	})
+}
+
+// layoutDialog dims the screen and draws a dialog in its center
+func layoutDialog(theme *material.Theme, gtx layout.Context, d *dialog) layout.Dimensions {
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x80}, clip.Rect{Max: gtx.Constraints.Max}.Op())
+
+	return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Max.X = min(gtx.Constraints.Max.X, gtx.Dp(unit.Dp(480)))
+		gtx.Constraints.Min = image.Point{}
+		return layout.Background{}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return fillRounded(gtx, theme.Palette.Bg)
+		}, func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						title := material.H6(theme, d.title)
+						if d.isError {
+							title.Color = errorColor
+						}
+						return title.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Body1(theme, d.message).Layout),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if d.onConfirm == nil {
+							return material.Button(theme, &d.okButton, "OK").Layout(gtx)
+						}
+						cancelLabel := d.cancelLabel
+						if cancelLabel == "" {
+							cancelLabel = "Cancel"
+						}
+						return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+							layout.Rigid(material.Button(theme, &d.okButton, d.confirmLabel).Layout),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+							layout.Rigid(material.Button(theme, &d.cancelButton, cancelLabel).Layout),
+						)
+					}),
+				)
+			})
+		})
+	})
+}
+
+// fillRounded fills the minimum constraints with a rounded rectangle
+func fillRounded(gtx layout.Context, fill color.NRGBA) layout.Dimensions {
+	rect := image.Rectangle{Max: gtx.Constraints.Min}
+	paint.FillShape(gtx.Ops, fill, clip.UniformRRect(rect, gtx.Dp(unit.Dp(4))).Op(gtx.Ops))
+	return layout.Dimensions{Size: gtx.Constraints.Min}
+}
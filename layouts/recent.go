@@ -0,0 +1,30 @@
+package layouts
+
+import (
+	"log"
+
+	"example.com/main/project"
+)
+
+// loadRecent reads the recent projects list from the user config directory
+func loadRecent() (*project.RecentProjects, error) {
+	path, err := project.RecentProjectsPath()
+	if err != nil {
+		return nil, err
+	}
+	return project.LoadRecent(path)
+}
+
+// updateRecent applies a change to the recent projects list and saves it;
+// the list is a convenience, so failures are only logged
+func updateRecent(update func(recent *project.RecentProjects)) {
+	recent, err := loadRecent()
+	if err != nil {
+		log.Printf("Failed to load recent projects: %v", err)
+		return
+	}
+	update(recent)
+	if err := recent.Save(); err != nil {
+		log.Printf("Failed to save recent projects: %v", err)
+	}
+}
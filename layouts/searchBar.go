@@ -0,0 +1,86 @@
+package layouts
+
+import (
+	"sort"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"example.com/main/translator"
+)
+
+// searchBar is the query editor and the source, status and warning filters
+// of the project workspace
+type searchBar struct {
+	query         widget.Editor
+	sourceButton  widget.Clickable
+	statusButton  widget.Clickable
+	warningsCheck widget.Bool
+
+	sources []string // source books of the loaded entities
+	source  int      // index into sources plus one; zero matches every source
+	status  int      // index into translator.Statuses plus one; zero matches every status
+}
+
+// newSearchBar creates a search bar offering the sources found in entries
+func newSearchBar(entries []translator.Entry) *searchBar {
+	b := &searchBar{}
+	b.query.SingleLine = true
+
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if source, ok := entry.Source["source"].(string); ok && !seen[source] {
+			seen[source] = true
+			b.sources = append(b.sources, source)
+		}
+	}
+	sort.Strings(b.sources)
+	return b
+}
+
+// filter returns the filter the bar describes for the entities of category
+func (b *searchBar) filter(category string) translator.EntryFilter {
+	filter := translator.EntryFilter{
+		Query:        b.query.Text(),
+		Category:     category,
+		WarningsOnly: b.warningsCheck.Value,
+	}
+	if b.source > 0 {
+		filter.Source = b.sources[b.source-1]
+	}
+	if b.status > 0 {
+		filter.Status = translator.Statuses[b.status-1]
+	}
+	return filter
+}
+
+// Layout draws the bar; the filter buttons cycle through their values
+func (b *searchBar) Layout(theme *material.Theme, gtx layout.Context) layout.Dimensions {
+	if b.sourceButton.Clicked(gtx) {
+		b.source = (b.source + 1) % (len(b.sources) + 1)
+	}
+	if b.statusButton.Clicked(gtx) {
+		b.status = (b.status + 1) % (len(translator.Statuses) + 1)
+	}
+
+	filter := b.filter("")
+	source, status := "all", "all"
+	if filter.Source != "" {
+		source = filter.Source
+	}
+	if filter.Status != "" {
+		status = string(filter.Status)
+	}
+
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, material.Editor(theme, &b.query, "Search names and text").Layout),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Rigid(material.Button(theme, &b.sourceButton, "Source: "+source).Layout),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Rigid(material.Button(theme, &b.statusButton, "Status: "+status).Layout),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Rigid(material.CheckBox(theme, &b.warningsCheck, "QA warnings").Layout),
+	)
+}
@@ -0,0 +1,201 @@
+package layouts
+
+import (
+	"fmt"
+	"image/color"
+	"unicode/utf8"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"example.com/main/translator"
+)
+
+var (
+	tagColor     = color.NRGBA{R: 0x4a, G: 0x7a, B: 0xc8, A: 0x30}
+	lockedColor  = color.NRGBA{R: 0x4a, G: 0x7a, B: 0xc8, A: 0x50}
+	problemColor = color.NRGBA{R: 0xd0, A: 0x60}
+)
+
+// tagEditor is a text editor for strings with 5etools inline tags. It
+// highlights tags, rejects edits to tag names and link targets, suggests tag
+// names and targets while typing and reports unbalanced braces.
+type tagEditor struct {
+	editor    widget.Editor
+	completer *translator.TagCompleter
+	onEdit    func(before, after string) // called for accepted edits of the user
+
+	text     string // last accepted text
+	caret    [2]int // selection before the last change, in runes
+	rejected bool
+	spans    []translator.LockedSpan
+	tags     [][2]int // byte ranges of every tag, nested ones included
+	problems []translator.TagProblem
+
+	suggestionStart   int // byte offset the suggestions replace from
+	suggestionEnd     int
+	suggestions       []string
+	suggestionButtons []widget.Clickable
+	regions           []widget.Region
+}
+
+// SetText replaces the text without the edit checks
+func (e *tagEditor) SetText(text string) {
+	e.editor.SetText(text)
+	e.accept(text)
+	e.suggestions = nil
+}
+
+// Text returns the current text
+func (e *tagEditor) Text() string {
+	return e.editor.Text()
+}
+
+// accept makes text the last accepted text and analyzes its tags
+func (e *tagEditor) accept(text string) {
+	e.text = text
+	e.spans = translator.LockedSpans(text)
+	e.problems = translator.CheckTagSyntax(text)
+	e.tags = e.tags[:0]
+	for _, span := range e.spans {
+		tag := [2]int{span.TagStart, span.TagEnd}
+		if len(e.tags) == 0 || e.tags[len(e.tags)-1] != tag {
+			e.tags = append(e.tags, tag)
+		}
+	}
+}
+
+// onChange accepts or reverts an edit and updates the suggestions
+func (e *tagEditor) onChange() {
+	text := e.editor.Text()
+	if !translator.TagEditAllowed(e.text, text) {
+		e.editor.SetText(e.text)
+		e.editor.SetCaret(e.caret[0], e.caret[1])
+		e.rejected = true
+		return
+	}
+	e.rejected = false
+	e.edited(text)
+
+	e.suggestions = nil
+	start, end := e.editor.Selection()
+	if e.completer != nil && start == end {
+		caret := byteOffset(text, end)
+		e.suggestionStart, e.suggestions = e.completer.Complete(text, caret)
+		e.suggestionEnd = caret
+	}
+	if len(e.suggestionButtons) < len(e.suggestions) {
+		e.suggestionButtons = make([]widget.Clickable, len(e.suggestions))
+	}
+}
+
+// complete replaces the typed part of a tag with a suggestion
+func (e *tagEditor) complete(suggestion string) {
+	e.editor.SetCaret(utf8.RuneCountInString(e.text[:e.suggestionStart]), utf8.RuneCountInString(e.text[:e.suggestionEnd]))
+	e.editor.Insert(suggestion)
+	e.edited(e.editor.Text())
+	e.suggestions = nil
+}
+
+// edited accepts a change of the user and reports it
+func (e *tagEditor) edited(text string) {
+	before := e.text
+	e.accept(text)
+	if e.onEdit != nil && before != text {
+		e.onEdit(before, text)
+	}
+}
+
+func (e *tagEditor) Layout(theme *material.Theme, gtx layout.Context, hint string) layout.Dimensions {
+	for i, suggestion := range e.suggestions {
+		if e.suggestionButtons[i].Clicked(gtx) {
+			e.complete(suggestion)
+			break
+		}
+	}
+
+	e.caret[0], e.caret[1] = e.editor.Selection()
+	for {
+		event, ok := e.editor.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := event.(widget.ChangeEvent); ok {
+			e.onChange()
+		}
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			// The highlights are painted below the text, where the editor places it
+			macro := op.Record(gtx.Ops)
+			dims := material.Editor(theme, &e.editor, hint).Layout(gtx)
+			call := macro.Stop()
+
+			for _, tag := range e.tags {
+				e.paintRange(gtx, tag[0], tag[1], tagColor)
+			}
+			for _, span := range e.spans {
+				e.paintRange(gtx, span.Start, span.End, lockedColor)
+			}
+			for _, problem := range e.problems {
+				e.paintRange(gtx, problem.Offset, problem.Offset+1, problemColor)
+			}
+			call.Add(gtx.Ops)
+			return dims
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			var message string
+			switch {
+			case e.rejected:
+				message = "Tag names and link targets can't be edited"
+			case len(e.problems) > 0:
+				problem := e.problems[0]
+				message = fmt.Sprintf("%s at character %d", problem.Message, utf8.RuneCountInString(e.text[:problem.Offset])+1)
+			default:
+				return layout.Dimensions{}
+			}
+			label := material.Caption(theme, message)
+			label.Color = errorColor
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			children := make([]layout.FlexChild, len(e.suggestions))
+			for i, suggestion := range e.suggestions {
+				children[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Right: unit.Dp(4), Top: unit.Dp(2)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(theme, &e.suggestionButtons[i], suggestion)
+						btn.TextSize = theme.TextSize * 0.8
+						btn.Inset = layout.UniformInset(unit.Dp(4))
+						return btn.Layout(gtx)
+					})
+				})
+			}
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+		}),
+	)
+}
+
+// paintRange fills the regions of the text between two byte offsets
+func (e *tagEditor) paintRange(gtx layout.Context, start, end int, fill color.NRGBA) {
+	e.regions = e.editor.Regions(utf8.RuneCountInString(e.text[:start]), utf8.RuneCountInString(e.text[:end]), e.regions[:0])
+	for _, region := range e.regions {
+		paint.FillShape(gtx.Ops, fill, clip.Rect(region.Bounds).Op())
+	}
+}
+
+// byteOffset converts a rune offset into text to a byte offset
+func byteOffset(text string, runes int) int {
+	for i := range text {
+		if runes == 0 {
+			return i
+		}
+		runes--
+	}
+	return len(text)
+}
@@ -31,57 +31,303 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"example.com/main/exporter"
+	"example.com/main/project"
 	"example.com/main/translator"
 )
 
 func main() {
+	// The first argument selects the command: translate (default), package, preview or extract
+	command := "translate"
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command = args[0]
+		args = args[1:]
+	}
+	if command != "translate" && command != "package" && command != "preview" && command != "extract" {
+		log.Fatalf("Unknown command: %s (expected translate, package, preview or extract)", command)
+	}
+
 	// Define command line flags
 	dataPath := flag.String("data", "data", "Path to the data directory containing source files")
 	dictionaryPath := flag.String("dictionary", "dictionary", "Path to the dictionary directory containing translation files")
 	exportPath := flag.String("export", "export", "Path to the export directory for translated files")
+	strict := flag.Bool("strict", false, "Fail on any invalid, orphaned, stale, tag mismatched or cross-reference issue")
+	failOnInvalid := flag.Bool("fail-on-invalid", false, "Fail on dictionary entries without origin_name/origin_source")
+	failOnOrphans := flag.Bool("fail-on-orphans", false, "Fail on dictionary entries that match no source entity")
+	failOnStale := flag.Bool("fail-on-stale", false, "Fail on dictionary entries whose origin_hash no longer matches the source")
+	failOnTagMismatch := flag.Bool("fail-on-tag-mismatch", false, "Fail when translated entries reference different tags than the source")
+	failOnCrossReference := flag.Bool("fail-on-cross-reference", false, "Fail when reference tags display a different name than the translation of the linked entity")
+	fixCrossReferences := flag.Bool("fix-cross-references", false, "Rewrite reference tags to display the translated name of the linked entity")
+
+	format := flag.String("format", "data", "Export format: data (translated 5etools data files), homebrew (also write a Plutonium homebrew file) babele (Foundry Babele compendium translations) or search (search index of translated names)")
+	namingPattern := flag.String("naming-pattern", "", "Format of translated names, e.g. \"{translated} [{original}]\" (default: the project setting, else {translated} [{original}])")
+	aliases := flag.Bool("aliases", false, "Add the original name and dictionary aliases to the alias array of translated entities")
+	resource := flag.Bool("resource", false, "Move translated entities to their own homebrew sources instead of overwriting the originals")
+	jobs := flag.Int("j", 0, "Number of data files processed concurrently (0 uses all CPUs)")
+	streamThreshold := flag.Int64("stream-threshold", 8<<20, "Stream data files of at least this many bytes instead of loading them whole (0 disables)")
+	force := flag.Bool("force", false, "Ignore the build cache and rebuild every output")
+	projectPath := flag.String("project", "", "Path to a "+project.Extension+" project file; other flags override its settings")
+	saveProjectPath := flag.String("save-project", "", "Write the effective settings to this "+project.Extension+" project file")
+	originReport := flag.Bool("origin-report", false, "Write origins.json to each export directory listing the dictionary layer of every translated string")
+	localeFilter := flag.String("locale", "", "Comma separated project locales to build (default: all)")
+	packageVersion := flag.String("version", "", "package: module version, overriding the project file")
+	packageOutput := flag.String("output", "", "package, preview: directory the module and zip or the preview pages are written to, overriding the project file")
+	previewFormat := flag.String("preview-format", "html", "preview: page format, html or md")
+
+	flag.CommandLine.Parse(args)
+
+	// Get the working directory to resolve relative paths
+	execDir, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("Failed to get current directory: %v", err)
+	}
+
+	// Load the project file or start from the default settings
+	var proj *project.Project
+	if *projectPath != "" {
+		proj, err = project.Load(*projectPath)
+		if err != nil {
+			log.Fatalf("Failed to load project: %v", err)
+		}
+	} else {
+		proj = project.New(filepath.Join(execDir, "project"+project.Extension))
+	}
+
+	// Command line flags override project settings
+	absPath := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(execDir, path)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "data":
+			proj.DataPath = absPath(*dataPath)
+		case "dictionary":
+			proj.DictionaryPaths = []string{absPath(*dictionaryPath)}
+		case "export":
+			proj.ExportPath = absPath(*exportPath)
+		case "version":
+			proj.Package.Version = *packageVersion
+		case "output":
+			if command == "preview" {
+				proj.Preview.Directory = absPath(*packageOutput)
+			} else {
+				proj.Package.OutputPath = absPath(*packageOutput)
+			}
+		case "naming-pattern":
+			proj.NamingPattern = *namingPattern
+		}
+	})
+
+	if *resource {
+		proj.Homebrew.Resource = true
+	}
+	if *aliases {
+		proj.GenerateAliases = true
+	}
 
-	flag.Parse()
+	options := proj.TranslatorOptions()
+	options.FailOnInvalid = options.FailOnInvalid || *failOnInvalid
+	options.FailOnOrphans = options.FailOnOrphans || *failOnOrphans
+	options.FailOnStale = options.FailOnStale || *failOnStale
+	options.FailOnTagMismatch = options.FailOnTagMismatch || *failOnTagMismatch
+	options.FailOnCrossReference = options.FailOnCrossReference || *failOnCrossReference
+	options.FixCrossReferences = options.FixCrossReferences || *fixCrossReferences
+	if *strict {
+		options = translator.StrictOptions()
+		options.NamingPattern = proj.NamingPattern
+		options.GenerateAliases = proj.GenerateAliases
+		options.FixCrossReferences = proj.QA.FixCrossReferences || *fixCrossReferences
+	}
+	options.Force = *force
+	options.Jobs = *jobs
+	options.StreamThreshold = *streamThreshold
+
+	if *saveProjectPath != "" {
+		proj.QA.Strict = proj.QA.Strict || *strict
+		proj.QA.FailOnInvalid = options.FailOnInvalid
+		proj.QA.FailOnOrphans = options.FailOnOrphans
+		proj.QA.FailOnStale = options.FailOnStale
+		proj.QA.FailOnTagMismatch = options.FailOnTagMismatch
+		proj.QA.FailOnCrossReference = options.FailOnCrossReference
+		proj.QA.FixCrossReferences = options.FixCrossReferences
+		err = proj.SaveAs(absPath(*saveProjectPath))
+		if err != nil {
+			log.Fatalf("Failed to save project: %v", err)
+		}
+		fmt.Printf("Project saved to: %s\n", proj.Path())
+	}
+
+	if *format != "data" && *format != "homebrew" && *format != "babele" && *format != "search" {
+		log.Fatalf("Unknown export format: %s", *format)
+	}
+	if command == "package" && (*format == "babele" || *format == "search") {
+		// Babele and search exports do not write the data files a module packages
+		log.Fatalf("The package command does not support the %s format (expected data or homebrew)", *format)
+	}
+
+	*dataPath = proj.Resolve(proj.DataPath)
 
 	// Validate paths exist
 	if _, err := os.Stat(*dataPath); os.IsNotExist(err) {
 		log.Fatalf("Data directory does not exist: %s", *dataPath)
 	}
 
-	if _, err := os.Stat(*dictionaryPath); os.IsNotExist(err) {
-		log.Fatalf("Dictionary directory does not exist: %s", *dictionaryPath)
+	// Select the locales to build
+	locales := proj.LocaleSettings()
+	if *localeFilter != "" {
+		selected := make(map[string]bool)
+		for _, code := range strings.Split(*localeFilter, ",") {
+			selected[strings.TrimSpace(code)] = true
+		}
+		var filtered []project.LocaleSettings
+		for _, locale := range locales {
+			if selected[locale.Code] {
+				filtered = append(filtered, locale)
+			}
+		}
+		if len(filtered) == 0 {
+			log.Fatalf("No project locale matches: %s", *localeFilter)
+		}
+		locales = filtered
 	}
 
-	// Get the directory of the executable to resolve relative paths
-	execDir, err := os.Getwd()
-	if err != nil {
-		log.Fatalf("Failed to get current directory: %v", err)
+	fmt.Printf("Starting translation process...\n")
+	fmt.Printf("Data path: %s\n", *dataPath)
+
+	// Build one export per locale and report each locale separately
+	failed := 0
+	stats := make([]translator.Stats, len(locales))
+	for i, locale := range locales {
+		stats[i], err = translateLocale(command, *format, proj.ForLocale(locale), options, *originReport, exporter.PreviewFormat(*previewFormat))
+		if err != nil {
+			log.Printf("Locale %s failed: %v", locale.Code, err)
+			failed++
+		}
 	}
 
-	// Resolve relative paths
-	if !filepath.IsAbs(*dataPath) {
-		*dataPath = filepath.Join(execDir, *dataPath)
+	// The locale column fits the longest code, such as uk-x-homebrew
+	width := len("Locale")
+	for _, locale := range locales {
+		if len(locale.Code) > width {
+			width = len(locale.Code)
+		}
 	}
-	if !filepath.IsAbs(*dictionaryPath) {
-		*dictionaryPath = filepath.Join(execDir, *dictionaryPath)
+	fmt.Printf("\n%-*s  Files  Cached  Translated      Coverage  Warnings\n", width, "Locale")
+	for i, locale := range locales {
+		s := stats[i]
+		fmt.Printf("%-*s  %5d  %6d  %5d/%-5d  %7.1f%%  %8d\n", width, locale.Code, s.Files, s.SkippedFiles, s.Translated, s.SourceEntities, s.Coverage(), s.Issues)
 	}
-	if !filepath.IsAbs(*exportPath) {
-		*exportPath = filepath.Join(execDir, *exportPath)
+
+	if failed > 0 {
+		log.Fatalf("Translation failed for %d of %d locale(s)", failed, len(locales))
+	}
+	fmt.Printf("Translation completed successfully!\n")
+}
+
+// translateLocale builds the export of a single locale project view
+func translateLocale(command, format string, proj *project.Project, options translator.Options, originReport bool, previewFormat exporter.PreviewFormat) (translator.Stats, error) {
+	exportPath := proj.Resolve(proj.ExportPath)
+
+	fmt.Printf("\n[%s] Export path: %s\n", proj.Locale, exportPath)
+	for _, path := range proj.DictionaryPaths {
+		if _, err := os.Stat(proj.Resolve(path)); os.IsNotExist(err) && command != "extract" {
+			return translator.Stats{}, fmt.Errorf("dictionary directory does not exist: %s", proj.Resolve(path))
+		}
+		fmt.Printf("[%s] Dictionary path: %s\n", proj.Locale, proj.Resolve(path))
 	}
 
 	// Create translator and run translation
-	translatorInstance := translator.NewTranslator(*dataPath, *dictionaryPath, *exportPath)
+	translatorInstance := proj.NewTranslator()
+	translatorInstance.SetOptions(options)
 
-	fmt.Printf("Starting translation process...\n")
-	fmt.Printf("Data path: %s\n", *dataPath)
-	fmt.Printf("Dictionary path: %s\n", *dictionaryPath)
-	fmt.Printf("Export path: %s\n", *exportPath)
+	// Extract scaffolds the dictionary instead of translating
+	if command == "extract" {
+		added, err := translatorInstance.Extract()
+		if err != nil {
+			return translator.Stats{}, fmt.Errorf("extract failed: %w", err)
+		}
+		fmt.Printf("[%s] Added %d dictionary entries to: %s\n", proj.Locale, added, proj.Resolve(proj.DictionaryPaths[0]))
+		return translator.Stats{}, nil
+	}
 
-	err = translatorInstance.Translate()
+	// Previews, Babele and search exports only need the merged entries, not translated data files
+	if command == "preview" || format == "babele" || format == "search" {
+		entries, err := translatorInstance.Entries()
+		if err != nil {
+			return translator.Stats{}, fmt.Errorf("translation failed: %w", err)
+		}
+		// Fail before writing anything, like Translate does
+		if err := translatorInstance.StrictError(); err != nil {
+			return translatorInstance.Stats(), fmt.Errorf("translation failed: strict mode: %w", err)
+		}
+
+		if command == "preview" {
+			indexPath, err := exporter.WritePreview(proj.PreviewPath(), entries, previewFormat)
+			if err != nil {
+				return translator.Stats{}, fmt.Errorf("preview failed: %w", err)
+			}
+			fmt.Printf("[%s] Preview of %d entities written to: %s\n", proj.Locale, len(entries), indexPath)
+		} else if format == "babele" {
+			written, err := exporter.WriteBabele(proj.BabelePath(), entries, proj.Babele.Packs)
+			if err != nil {
+				return translator.Stats{}, fmt.Errorf("babele export failed: %w", err)
+			}
+			fmt.Printf("[%s] Wrote %d Babele compendium file(s) to: %s\n", proj.Locale, len(written), proj.BabelePath())
+		} else {
+			count, err := exporter.WriteSearchIndex(proj.SearchPath(), entries)
+			if err != nil {
+				return translator.Stats{}, fmt.Errorf("search index export failed: %w", err)
+			}
+			fmt.Printf("[%s] Wrote %d search index entries to: %s\n", proj.Locale, count, proj.SearchPath())
+		}
+
+		return translatorInstance.Stats(), nil
+	}
+
+	err := translatorInstance.Translate()
 	if err != nil {
-		log.Fatalf("Translation failed: %v", err)
+		return translatorInstance.Stats(), fmt.Errorf("translation failed: %w", err)
 	}
 
-	fmt.Printf("Translation completed successfully!\n")
-	fmt.Printf("Translated files written to: %s\n", *exportPath)
+	var layers []string
+	for _, count := range translatorInstance.LayerCounts() {
+		layers = append(layers, fmt.Sprintf("%s %d", count.Layer, count.Strings))
+	}
+	fmt.Printf("[%s] Strings by layer: %s\n", proj.Locale, strings.Join(layers, ", "))
+
+	if originReport {
+		reportPath := filepath.Join(exportPath, "origins.json")
+		err = translatorInstance.WriteOriginReport(reportPath)
+		if err != nil {
+			return translatorInstance.Stats(), err
+		}
+		fmt.Printf("[%s] Origin report written to: %s\n", proj.Locale, reportPath)
+	}
+
+	if format == "homebrew" {
+		homebrewPath := proj.HomebrewPath()
+		err = exporter.WriteHomebrew(translatorInstance.ExportPath(), translatorInstance.Outputs(), homebrewPath, proj.HomebrewOptions())
+		if err != nil {
+			return translatorInstance.Stats(), fmt.Errorf("homebrew export failed: %w", err)
+		}
+		fmt.Printf("[%s] Homebrew written to: %s\n", proj.Locale, homebrewPath)
+	}
+
+	if command == "package" {
+		moduleDir, zipPath, err := exporter.PackageFoundryModule(proj.FoundryModule(), translatorInstance.ExportPath(), translatorInstance.Outputs(), proj.PackagePath())
+		if err != nil {
+			return translatorInstance.Stats(), fmt.Errorf("packaging failed: %w", err)
+		}
+		fmt.Printf("[%s] Foundry module written to: %s\n", proj.Locale, moduleDir)
+		fmt.Printf("[%s] Foundry module archive: %s\n", proj.Locale, zipPath)
+	}
+
+	fmt.Printf("[%s] Translated files written to: %s\n", proj.Locale, exportPath)
+	return translatorInstance.Stats(), nil
 }
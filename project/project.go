@@ -0,0 +1,566 @@
+// Package project reads and writes .langproj project files shared by the
+// command line translator and the GUI. A minimal project file looks like:
+//
+//	{
+//	    "version": 1,
+//	    "name": "Ukrainian translation",
+//	    "dataPath": "data",
+//	    "dictionaryPaths": ["dictionary"],
+//	    "exportPath": "export",
+//	    "locale": "uk",
+//	    "categories": ["background"]
+//	}
+//
+// Relative paths are resolved against the directory containing the project file.
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"example.com/main/exporter"
+	"example.com/main/translator"
+)
+
+// Extension is the file extension of project files
+const Extension = ".langproj"
+
+// FormatVersion is the project file format version written by Save
+const FormatVersion = 1
+
+// QASettings selects which dictionary issues fail a build and whether
+// reference tags are rewritten to show the translated names they link to
+type QASettings struct {
+	Strict            bool `json:"strict"`
+	FailOnInvalid     bool `json:"failOnInvalid"`
+	FailOnOrphans     bool `json:"failOnOrphans"`
+	FailOnStale       bool `json:"failOnStale"`
+	FailOnTagMismatch bool `json:"failOnTagMismatch"`
+
+	FailOnCrossReference bool `json:"failOnCrossReference"`
+	FixCrossReferences   bool `json:"fixCrossReferences"`
+}
+
+// HomebrewSettings configures the Plutonium homebrew export
+type HomebrewSettings struct {
+	File     string                    `json:"file,omitempty"`    // relative to the export path
+	Resource bool                      `json:"resource"`          // move translated entities to their own source IDs
+	Sources  []exporter.HomebrewSource `json:"sources,omitempty"` // sources declared in _meta
+
+	// Dates written to _meta, such as "2025-01-31T00:00:00Z"; omitted when unset
+	DateAdded        time.Time `json:"dateAdded,omitzero"`
+	DateLastModified time.Time `json:"dateLastModified,omitzero"`
+}
+
+// BabeleSettings configures the Foundry Babele compendium export
+type BabeleSettings struct {
+	Directory string                         `json:"directory,omitempty"` // relative to the export path
+	Packs     map[string]exporter.BabelePack `json:"packs,omitempty"`     // compendium overrides per category
+}
+
+// SearchSettings configures the translated search index export
+type SearchSettings struct {
+	File string `json:"file,omitempty"` // relative to the export path
+}
+
+// PreviewSettings configures the pages written by the preview command
+type PreviewSettings struct {
+	Directory string `json:"directory,omitempty"` // relative to the export path
+}
+
+// PackageSettings configures the Foundry VTT module built by the package
+// command; OutputPath and the language file are relative to the project directory
+type PackageSettings struct {
+	exporter.FoundryModule
+	OutputPath string `json:"outputPath,omitempty"`
+}
+
+// LocaleSettings describes one target locale of a project
+type LocaleSettings struct {
+	Code            string   `json:"code"`
+	Name            string   `json:"name,omitempty"`
+	DictionaryPaths []string `json:"dictionaryPaths,omitempty"` // default dictionary/<code>
+	ExportPath      string   `json:"exportPath,omitempty"`      // default <exportPath>/<code>
+	// Fallbacks are the locales whose dictionaries translate what this locale
+	// leaves out, tried in order and followed by their own fallbacks; strings
+	// no locale translates keep the English source text
+	Fallbacks []string `json:"fallbacks,omitempty"`
+}
+
+// Project holds the settings stored in a .langproj file
+type Project struct {
+	Version int    `json:"version"`
+	Name    string `json:"name,omitempty"`
+
+	// DataPath is the 5etools data directory
+	DataPath string `json:"dataPath"`
+	// DictionaryPaths are the dictionary directories, later ones read after earlier ones
+	DictionaryPaths []string `json:"dictionaryPaths"`
+	// ExportPath is where translated files are written
+	ExportPath string `json:"exportPath"`
+	// Locale is the single target locale of a project without Locales
+	Locale string `json:"locale"`
+	// Locales are the target locales built in a single run
+	Locales []LocaleSettings `json:"locales,omitempty"`
+	// Categories are the 5etools entity categories that are translated
+	Categories []string `json:"categories"`
+	// NamingPattern formats translated names from {translated} and {original},
+	// so dictionaries only store the bare translated name
+	NamingPattern string `json:"namingPattern,omitempty"`
+	// GenerateAliases adds the original name and dictionary aliases to the
+	// alias array of translated entities
+	GenerateAliases bool `json:"generateAliases,omitempty"`
+
+	QA       QASettings       `json:"qa"`
+	Homebrew HomebrewSettings `json:"homebrew"`
+	Babele   BabeleSettings   `json:"babele"`
+	Search   SearchSettings   `json:"search"`
+	Preview  PreviewSettings  `json:"preview"`
+	Package  PackageSettings  `json:"package"`
+
+	path      string
+	fallbacks []LocaleSettings // fallback chain of a single locale view
+}
+
+// New creates a project with default settings that will be saved to path
+func New(path string) *Project {
+	return &Project{
+		Version:         FormatVersion,
+		DataPath:        "data",
+		DictionaryPaths: []string{"dictionary"},
+		ExportPath:      "export",
+		Locale:          "uk",
+		Categories:      []string{"background"},
+		NamingPattern:   translator.DefaultNamingPattern,
+		path:            path,
+	}
+}
+
+// ValidateDataPath checks that dataPath is a directory of 5etools data files
+// and returns the categories found in it
+func ValidateDataPath(dataPath string) ([]string, error) {
+	info, err := os.Stat(dataPath)
+	if err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("data directory %s does not exist", dataPath)
+	}
+	categories := translator.DetectCategories(dataPath)
+	if len(categories) == 0 {
+		return nil, fmt.Errorf("no 5etools data files found in %s", dataPath)
+	}
+	return categories, nil
+}
+
+// ValidateProjectPath checks that a new project file can be created at path
+func ValidateProjectPath(path string) error {
+	if filepath.Ext(path) != Extension {
+		return fmt.Errorf("project file %s must have the %s extension", path, Extension)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("project file %s already exists", path)
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err == nil && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", filepath.Dir(path))
+	}
+	return nil
+}
+
+// ProjectFilePath turns a save location into an absolute project file path:
+// a directory gets a project file named after it and a missing extension is added
+func ProjectFilePath(savePath string) (string, error) {
+	if info, err := os.Stat(savePath); err == nil && info.IsDir() {
+		savePath = filepath.Join(savePath, filepath.Base(filepath.Clean(savePath))+Extension)
+	} else if filepath.Ext(savePath) != Extension {
+		savePath += Extension
+	}
+	return filepath.Abs(savePath)
+}
+
+// Create sets up a new project at path for the 5etools data in dataPath: it
+// checks that the data directory holds known data files, enables their
+// categories, creates the dictionary and export directories and writes the
+// project file. An existing project file is never overwritten.
+func Create(path, dataPath string) (*Project, error) {
+	err := ValidateProjectPath(path)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := ValidateDataPath(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	p := New(path)
+	p.Name = strings.TrimSuffix(filepath.Base(path), Extension)
+	p.DataPath = dataPath
+	if rel, err := filepath.Rel(p.Dir(), dataPath); err == nil && filepath.IsAbs(dataPath) {
+		p.DataPath = rel
+	}
+	p.Categories = categories
+
+	for _, dir := range []string{p.DictionaryPaths[0], p.ExportPath} {
+		err = os.MkdirAll(p.Resolve(dir), 0755)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create project directory: %w", err)
+		}
+	}
+
+	err = p.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Load reads a project file
+func Load(path string) (*Project, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project file %s: %w", path, err)
+	}
+
+	p := New(path)
+	err = json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal project file %s: %w", path, err)
+	}
+
+	if p.Version > FormatVersion {
+		return nil, fmt.Errorf("project file %s has unsupported version %d", path, p.Version)
+	}
+
+	err = p.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid project file %s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// Save writes the project to the file it was loaded from or created for
+func (p *Project) Save() error {
+	return p.SaveAs(p.path)
+}
+
+// SaveAs writes the project to path and remembers it for later saves
+func (p *Project) SaveAs(path string) error {
+	p.Version = FormatVersion
+
+	err := p.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid project: %w", err)
+	}
+
+	// Keep relative paths pointing at the same directories from the new location
+	if p.path != "" && filepath.Dir(path) != p.Dir() {
+		p.DataPath = p.rebase(p.DataPath, filepath.Dir(path))
+		for i, dictionaryPath := range p.DictionaryPaths {
+			p.DictionaryPaths[i] = p.rebase(dictionaryPath, filepath.Dir(path))
+		}
+		p.ExportPath = p.rebase(p.ExportPath, filepath.Dir(path))
+		for i := range p.Locales {
+			for j, dictionaryPath := range p.Locales[i].DictionaryPaths {
+				p.Locales[i].DictionaryPaths[j] = p.rebase(dictionaryPath, filepath.Dir(path))
+			}
+			if p.Locales[i].ExportPath != "" {
+				p.Locales[i].ExportPath = p.rebase(p.Locales[i].ExportPath, filepath.Dir(path))
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(p, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal project: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create project directory: %w", err)
+	}
+
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write project file %s: %w", path, err)
+	}
+
+	p.path = path
+	return nil
+}
+
+// Validate checks that the project settings are usable
+func (p *Project) Validate() error {
+	if p.DataPath == "" {
+		return fmt.Errorf("dataPath is empty")
+	}
+	if len(p.DictionaryPaths) == 0 {
+		return fmt.Errorf("dictionaryPaths is empty")
+	}
+	if p.ExportPath == "" {
+		return fmt.Errorf("exportPath is empty")
+	}
+	seen := make(map[string]bool)
+	for _, locale := range p.Locales {
+		if locale.Code == "" {
+			return fmt.Errorf("locale without code")
+		}
+		if seen[locale.Code] {
+			return fmt.Errorf("duplicate locale %q", locale.Code)
+		}
+		seen[locale.Code] = true
+	}
+	for _, locale := range p.Locales {
+		for _, fallback := range locale.Fallbacks {
+			if !seen[fallback] {
+				return fmt.Errorf("locale %q falls back to unknown locale %q", locale.Code, fallback)
+			}
+		}
+	}
+	for _, category := range p.Categories {
+		if _, ok := translator.LookupCategory(category); !ok {
+			return fmt.Errorf("unknown category %q", category)
+		}
+	}
+	return translator.ValidateNamingPattern(p.NamingPattern)
+}
+
+// Path returns the location of the project file
+func (p *Project) Path() string {
+	return p.path
+}
+
+// Dir returns the directory relative project paths are resolved against
+func (p *Project) Dir() string {
+	return filepath.Dir(p.path)
+}
+
+// Resolve returns path resolved against the project directory
+func (p *Project) Resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.Dir(), path)
+}
+
+// LocaleSettings returns the effective settings of every target locale
+func (p *Project) LocaleSettings() []LocaleSettings {
+	if len(p.Locales) == 0 {
+		return []LocaleSettings{{
+			Code:            p.Locale,
+			DictionaryPaths: p.DictionaryPaths,
+			ExportPath:      p.ExportPath,
+		}}
+	}
+
+	locales := make([]LocaleSettings, 0, len(p.Locales))
+	for _, locale := range p.Locales {
+		if len(locale.DictionaryPaths) == 0 {
+			locale.DictionaryPaths = []string{filepath.Join(p.DictionaryPaths[0], locale.Code)}
+		}
+		if locale.ExportPath == "" {
+			locale.ExportPath = filepath.Join(p.ExportPath, locale.Code)
+		}
+		locales = append(locales, locale)
+	}
+	return locales
+}
+
+// FallbackChain returns the locales tried after the given one, in order
+func (p *Project) FallbackChain(locale LocaleSettings) []LocaleSettings {
+	settings := make(map[string]LocaleSettings)
+	for _, other := range p.LocaleSettings() {
+		settings[other.Code] = other
+	}
+
+	var chain []LocaleSettings
+	visited := map[string]bool{locale.Code: true}
+	var follow func(codes []string)
+	follow = func(codes []string) {
+		for _, code := range codes {
+			fallback, ok := settings[code]
+			if !ok || visited[code] {
+				continue
+			}
+			visited[code] = true
+			chain = append(chain, fallback)
+			follow(fallback.Fallbacks)
+		}
+	}
+	follow(locale.Fallbacks)
+
+	return chain
+}
+
+// ForLocale returns a single locale view of the project whose locale,
+// dictionary, export and fallback settings are those of the given locale
+func (p *Project) ForLocale(locale LocaleSettings) *Project {
+	view := *p
+	view.Locale = locale.Code
+	view.fallbacks = p.FallbackChain(locale)
+	view.Locales = nil
+	view.DictionaryPaths = append([]string(nil), locale.DictionaryPaths...)
+	view.ExportPath = locale.ExportPath
+
+	// Every locale is packaged as its own module
+	if len(p.Locales) > 1 {
+		if view.Package.ID != "" {
+			view.Package.ID += "-" + locale.Code
+		}
+		if view.Package.Language != "" && view.Package.Language != locale.Code {
+			view.Package.LanguageFile = ""
+		}
+		view.Package.Language = locale.Code
+	}
+	if locale.Name != "" {
+		view.Package.LanguageName = locale.Name
+	}
+
+	return &view
+}
+
+// rebase converts a relative project path so it is relative to dir instead
+func (p *Project) rebase(path, dir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(dir, p.Resolve(path))
+	if err != nil {
+		return p.Resolve(path)
+	}
+	return rel
+}
+
+// TranslatorOptions converts the QA, naming and alias settings into translator options
+func (p *Project) TranslatorOptions() translator.Options {
+	options := translator.Options{
+		FailOnInvalid:     p.QA.FailOnInvalid,
+		FailOnOrphans:     p.QA.FailOnOrphans,
+		FailOnStale:       p.QA.FailOnStale,
+		FailOnTagMismatch: p.QA.FailOnTagMismatch,
+
+		FailOnCrossReference: p.QA.FailOnCrossReference,
+	}
+	if p.QA.Strict {
+		options = translator.StrictOptions()
+	}
+	options.FixCrossReferences = p.QA.FixCrossReferences
+	options.NamingPattern = p.NamingPattern
+	options.GenerateAliases = p.GenerateAliases
+	return options
+}
+
+// HomebrewPath returns the location of the homebrew export file
+func (p *Project) HomebrewPath() string {
+	file := p.Homebrew.File
+	if file == "" {
+		file = filepath.Join("homebrew", "translation-"+p.Locale+".json")
+	}
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(p.Resolve(p.ExportPath), file)
+}
+
+// HomebrewOptions converts the homebrew settings into exporter options
+func (p *Project) HomebrewOptions() exporter.HomebrewOptions {
+	return exporter.HomebrewOptions{
+		Sources:  p.Homebrew.Sources,
+		Resource: p.Homebrew.Resource,
+		Language: p.Locale,
+
+		DateAdded:        p.Homebrew.DateAdded,
+		DateLastModified: p.Homebrew.DateLastModified,
+	}
+}
+
+// BabelePath returns the directory Babele translation files are written to
+func (p *Project) BabelePath() string {
+	directory := p.Babele.Directory
+	if directory == "" {
+		directory = "babele"
+	}
+	if filepath.IsAbs(directory) {
+		return directory
+	}
+	return filepath.Join(p.Resolve(p.ExportPath), directory)
+}
+
+// SearchPath returns the location of the search index file
+func (p *Project) SearchPath() string {
+	file := p.Search.File
+	if file == "" {
+		file = filepath.Join("search", "index-"+p.Locale+".json")
+	}
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(p.Resolve(p.ExportPath), file)
+}
+
+// PreviewPath returns the directory preview pages are written to
+func (p *Project) PreviewPath() string {
+	directory := p.Preview.Directory
+	if directory == "" {
+		directory = "preview"
+	}
+	if filepath.IsAbs(directory) {
+		return directory
+	}
+	return filepath.Join(p.Resolve(p.ExportPath), directory)
+}
+
+// PackagePath returns the directory Foundry module packages are written to
+func (p *Project) PackagePath() string {
+	outputPath := p.Package.OutputPath
+	if outputPath == "" {
+		outputPath = "dist"
+	}
+	return p.Resolve(outputPath)
+}
+
+// FoundryModule returns the module description with defaults filled in from the project
+func (p *Project) FoundryModule() exporter.FoundryModule {
+	module := p.Package.FoundryModule
+	if module.ID == "" {
+		module.ID = "plutonium-translation-" + p.Locale
+	}
+	if module.Title == "" {
+		module.Title = p.Name
+	}
+	if module.Title == "" {
+		module.Title = "Plutonium translation (" + p.Locale + ")"
+	}
+	if module.Version == "" {
+		module.Version = "1.0.0"
+	}
+	if module.Language == "" {
+		module.Language = p.Locale
+	}
+	if module.LanguageFile != "" {
+		module.LanguageFile = p.Resolve(module.LanguageFile)
+	}
+	return module
+}
+
+// NewTranslator creates a translator configured from the project
+func (p *Project) NewTranslator() *translator.Translator {
+	t := translator.NewTranslator(p.Resolve(p.DataPath), p.Resolve(p.DictionaryPaths[0]), p.Resolve(p.ExportPath))
+	for _, dictionaryPath := range p.DictionaryPaths[1:] {
+		t.AddDictionaryPath(p.Resolve(dictionaryPath))
+	}
+	t.SetLocale(p.Locale)
+	for _, fallback := range p.fallbacks {
+		var dictionaryPaths []string
+		for _, dictionaryPath := range fallback.DictionaryPaths {
+			dictionaryPaths = append(dictionaryPaths, p.Resolve(dictionaryPath))
+		}
+		t.AddFallback(fallback.Code, dictionaryPaths...)
+	}
+	t.SetCategories(p.Categories)
+	t.SetOptions(p.TranslatorOptions())
+	return t
+}
@@ -0,0 +1,243 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_project")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	projectPath := filepath.Join(tempDir, "uk"+Extension)
+	p := New(projectPath)
+	p.Name = "Ukrainian"
+	p.QA.FailOnOrphans = true
+
+	err = p.Save()
+	if err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+
+	loaded, err := Load(projectPath)
+	if err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+
+	if loaded.Name != "Ukrainian" {
+		t.Errorf("Expected name 'Ukrainian', got '%s'", loaded.Name)
+	}
+
+	if loaded.Resolve(loaded.DataPath) != filepath.Join(tempDir, "data") {
+		t.Errorf("Expected data path to resolve into project directory, got '%s'", loaded.Resolve(loaded.DataPath))
+	}
+
+	if !loaded.TranslatorOptions().FailOnOrphans {
+		t.Errorf("Expected FailOnOrphans to be loaded from qa settings")
+	}
+}
+
+func TestSaveAsRebasesRelativePaths(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_project")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	p := New(filepath.Join(tempDir, "project"+Extension))
+	err = p.SaveAs(filepath.Join(tempDir, "nested", "project"+Extension))
+	if err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+
+	if p.DataPath != filepath.Join("..", "data") {
+		t.Errorf("Expected data path '../data', got '%s'", p.DataPath)
+	}
+
+	if p.Resolve(p.DataPath) != filepath.Join(tempDir, "data") {
+		t.Errorf("Expected data path to keep pointing at the same directory, got '%s'", p.Resolve(p.DataPath))
+	}
+}
+
+func TestLoadRejectsUnknownCategory(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_project")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	projectPath := filepath.Join(tempDir, "broken"+Extension)
+	content := `{"version": 1, "dataPath": "data", "dictionaryPaths": ["dictionary"], "exportPath": "export", "categories": ["dragon"]}`
+	err = ioutil.WriteFile(projectPath, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write project file: %v", err)
+	}
+
+	_, err = Load(projectPath)
+	if err == nil {
+		t.Errorf("Expected error for unknown category")
+	}
+}
+
+func TestLocaleSettings(t *testing.T) {
+	p := New(filepath.Join("root", "project"+Extension))
+	p.Locales = []LocaleSettings{
+		{Code: "uk", Name: "Українська"},
+		{Code: "pl", DictionaryPaths: []string{"polish"}, ExportPath: "out/pl"},
+	}
+	p.Package.ID = "plutonium"
+	p.Package.Language = "uk"
+	p.Package.LanguageFile = "lang/uk.json"
+
+	locales := p.LocaleSettings()
+	if len(locales) != 2 {
+		t.Fatalf("Expected 2 locales, got %d", len(locales))
+	}
+
+	if locales[0].DictionaryPaths[0] != filepath.Join("dictionary", "uk") || locales[0].ExportPath != filepath.Join("export", "uk") {
+		t.Errorf("Expected default per-locale paths, got %v", locales[0])
+	}
+
+	uk := p.ForLocale(locales[0])
+	if uk.Locale != "uk" || uk.Resolve(uk.ExportPath) != filepath.Join("root", "export", "uk") {
+		t.Errorf("Expected uk view exporting to root/export/uk, got %s %s", uk.Locale, uk.ExportPath)
+	}
+	if uk.FoundryModule().ID != "plutonium-uk" || uk.FoundryModule().LanguageName != "Українська" {
+		t.Errorf("Expected uk module, got %v", uk.FoundryModule())
+	}
+
+	pl := p.ForLocale(locales[1])
+	if pl.DictionaryPaths[0] != "polish" || pl.ExportPath != "out/pl" {
+		t.Errorf("Expected configured pl paths, got %v %s", pl.DictionaryPaths, pl.ExportPath)
+	}
+	if pl.FoundryModule().LanguageFile != "" {
+		t.Errorf("Expected uk language file not to be packaged for pl")
+	}
+
+	if p.Locale != "uk" || len(p.Locales) != 2 {
+		t.Errorf("Expected ForLocale to leave the project unchanged")
+	}
+}
+
+func TestSingleLocaleSettings(t *testing.T) {
+	p := New("project" + Extension)
+
+	locales := p.LocaleSettings()
+	if len(locales) != 1 || locales[0].Code != "uk" || locales[0].ExportPath != "export" {
+		t.Errorf("Expected the project locale with the project paths, got %v", locales)
+	}
+}
+
+func TestFallbackChain(t *testing.T) {
+	p := New(filepath.Join("root", "project"+Extension))
+	p.Locales = []LocaleSettings{
+		{Code: "uk"},
+		{Code: "uk-x-homebrew", Fallbacks: []string{"uk-x-dialect", "uk"}},
+		{Code: "uk-x-dialect", Fallbacks: []string{"uk", "uk-x-homebrew"}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Expected a valid project, got %v", err)
+	}
+
+	locales := p.LocaleSettings()
+	chain := p.FallbackChain(locales[1])
+	if len(chain) != 2 || chain[0].Code != "uk-x-dialect" || chain[1].Code != "uk" {
+		t.Errorf("Expected uk-x-dialect then uk, got %v", chain)
+	}
+
+	p.Locales[0].Fallbacks = []string{"ru"}
+	if err := p.Validate(); err == nil {
+		t.Errorf("Expected an unknown fallback locale to be rejected")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_create")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	projectPath := filepath.Join(tempDir, "uk", "uk"+Extension)
+
+	if _, err := Create(projectPath, dataDir); err == nil {
+		t.Errorf("Expected a missing data directory to be rejected")
+	}
+
+	os.MkdirAll(dataDir, 0755)
+	if _, err := Create(projectPath, dataDir); err == nil {
+		t.Errorf("Expected a data directory without 5etools files to be rejected")
+	}
+
+	ioutil.WriteFile(filepath.Join(dataDir, "backgrounds.json"), []byte(`{"background": []}`), 0644)
+	ioutil.WriteFile(filepath.Join(dataDir, "feats.json"), []byte(`{"feat": []}`), 0644)
+	p, err := Create(projectPath, dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	if p.DataPath != filepath.Join("..", "data") {
+		t.Errorf("Expected a data path relative to the project, got %s", p.DataPath)
+	}
+	if len(p.Categories) != 2 || p.Categories[0] != "background" || p.Categories[1] != "feat" {
+		t.Errorf("Expected the detected categories, got %v", p.Categories)
+	}
+	for _, dir := range []string{"dictionary", "export"} {
+		if info, err := os.Stat(filepath.Join(tempDir, "uk", dir)); err != nil || !info.IsDir() {
+			t.Errorf("Expected the %s directory to be created", dir)
+		}
+	}
+
+	loaded, err := Load(projectPath)
+	if err != nil {
+		t.Fatalf("Failed to load created project: %v", err)
+	}
+	if loaded.Name != "uk" {
+		t.Errorf("Expected name 'uk', got '%s'", loaded.Name)
+	}
+
+	if _, err := Create(projectPath, dataDir); err == nil {
+		t.Errorf("Expected an existing project file not to be overwritten")
+	}
+}
+
+func TestProjectFilePath(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_project_file_path")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path, err := ProjectFilePath(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to resolve project file path: %v", err)
+	}
+	if expected := filepath.Join(tempDir, filepath.Base(tempDir)+Extension); path != expected {
+		t.Errorf("Expected %s for a directory, got %s", expected, path)
+	}
+
+	path, _ = ProjectFilePath(filepath.Join(tempDir, "uk"))
+	if expected := filepath.Join(tempDir, "uk"+Extension); path != expected {
+		t.Errorf("Expected %s for a path without extension, got %s", expected, path)
+	}
+	if err := ValidateProjectPath(path); err != nil {
+		t.Errorf("Expected a new project path to be valid, got %v", err)
+	}
+
+	ioutil.WriteFile(path, []byte("{}"), 0644)
+	if err := ValidateProjectPath(path); err == nil {
+		t.Errorf("Expected an existing project file to be rejected")
+	}
+	if err := ValidateProjectPath(filepath.Join(path, "nested"+Extension)); err == nil {
+		t.Errorf("Expected a project inside a file to be rejected")
+	}
+	if err := ValidateProjectPath(filepath.Join(tempDir, "uk.json")); err == nil {
+		t.Errorf("Expected a path with the wrong extension to be rejected")
+	}
+}
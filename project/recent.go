@@ -0,0 +1,137 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaxRecentProjects is the number of projects the recent list keeps
+const MaxRecentProjects = 10
+
+// RecentProject is an entry of the recent projects list
+type RecentProject struct {
+	Name       string    `json:"name"`
+	Path       string    `json:"path"`
+	Locale     string    `json:"locale"`
+	LastOpened time.Time `json:"lastOpened"`
+	Coverage   float64   `json:"coverage"` // translated share of source entities, in percent
+}
+
+// Exists reports whether the project file is still there
+func (r RecentProject) Exists() bool {
+	info, err := os.Stat(r.Path)
+	return err == nil && !info.IsDir()
+}
+
+// RecentProjects is the list of recently opened projects, most recent first
+type RecentProjects struct {
+	Projects []RecentProject `json:"projects"`
+
+	path string
+}
+
+// RecentProjectsPath returns the location of the recent projects list in the user config directory
+func RecentProjectsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "plutonium-translator", "recent.json"), nil
+}
+
+// LoadRecent reads the recent projects list, returning an empty list when it does not exist yet
+func LoadRecent(path string) (*RecentProjects, error) {
+	recent := &RecentProjects{path: path}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return recent, nil
+	}
+	if err != nil {
+		return recent, fmt.Errorf("failed to read recent projects %s: %w", path, err)
+	}
+
+	err = json.Unmarshal(data, recent)
+	if err != nil {
+		return &RecentProjects{path: path}, fmt.Errorf("failed to unmarshal recent projects %s: %w", path, err)
+	}
+	return recent, nil
+}
+
+// Save writes the recent projects list to the file it was loaded from
+func (r *RecentProjects) Save() error {
+	data, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recent projects: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(r.path), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	err = ioutil.WriteFile(r.path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write recent projects %s: %w", r.path, err)
+	}
+	return nil
+}
+
+// Touch moves a project to the top of the list and records when it was opened,
+// keeping the coverage measured last time
+func (r *RecentProjects) Touch(p *Project, opened time.Time) {
+	entry := RecentProject{Name: p.Name, Path: p.Path(), Locale: p.Locale, LastOpened: opened}
+	if entry.Name == "" {
+		entry.Name = filepath.Base(p.Path())
+	}
+
+	projects := []RecentProject{entry}
+	for _, recent := range r.Projects {
+		if recent.Path == entry.Path {
+			projects[0].Coverage = recent.Coverage
+			continue
+		}
+		projects = append(projects, recent)
+	}
+	if len(projects) > MaxRecentProjects {
+		projects = projects[:MaxRecentProjects]
+	}
+	r.Projects = projects
+}
+
+// SetCoverage records the translation coverage of a listed project
+func (r *RecentProjects) SetCoverage(path string, coverage float64) {
+	for i := range r.Projects {
+		if r.Projects[i].Path == path {
+			r.Projects[i].Coverage = coverage
+		}
+	}
+}
+
+// Remove drops a project from the list
+func (r *RecentProjects) Remove(path string) {
+	projects := r.Projects[:0]
+	for _, recent := range r.Projects {
+		if recent.Path != path {
+			projects = append(projects, recent)
+		}
+	}
+	r.Projects = projects
+}
+
+// RemoveMissing drops the projects whose files no longer exist and returns how many were removed
+func (r *RecentProjects) RemoveMissing() int {
+	projects := r.Projects[:0]
+	for _, recent := range r.Projects {
+		if recent.Exists() {
+			projects = append(projects, recent)
+		}
+	}
+	removed := len(r.Projects) - len(projects)
+	r.Projects = projects
+	return removed
+}
@@ -0,0 +1,67 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecentProjects(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_recent")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	recentPath := filepath.Join(tempDir, "config", "recent.json")
+	recent, err := LoadRecent(recentPath)
+	if err != nil || len(recent.Projects) != 0 {
+		t.Fatalf("Expected an empty list, got %v (%v)", recent.Projects, err)
+	}
+
+	uk := New(filepath.Join(tempDir, "uk"+Extension))
+	uk.Name = "Ukrainian"
+	if err := uk.Save(); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	pl := New(filepath.Join(tempDir, "pl"+Extension))
+	pl.Locale = "pl"
+
+	opened := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	recent.Touch(uk, opened)
+	recent.SetCoverage(uk.Path(), 42.5)
+	recent.Touch(pl, opened.Add(time.Hour))
+	recent.Touch(uk, opened.Add(2*time.Hour))
+
+	if len(recent.Projects) != 2 || recent.Projects[0].Path != uk.Path() {
+		t.Fatalf("Expected uk first, got %v", recent.Projects)
+	}
+	first := recent.Projects[0]
+	if first.Name != "Ukrainian" || first.Locale != "uk" || first.Coverage != 42.5 || !first.LastOpened.Equal(opened.Add(2*time.Hour)) {
+		t.Errorf("Unexpected recent project %+v", first)
+	}
+	if recent.Projects[1].Name != "pl"+Extension {
+		t.Errorf("Expected the file name for an unnamed project, got %s", recent.Projects[1].Name)
+	}
+
+	if err := recent.Save(); err != nil {
+		t.Fatalf("Failed to save recent projects: %v", err)
+	}
+	loaded, err := LoadRecent(recentPath)
+	if err != nil || len(loaded.Projects) != 2 {
+		t.Fatalf("Expected 2 saved projects, got %v (%v)", loaded.Projects, err)
+	}
+
+	if loaded.Projects[1].Exists() {
+		t.Errorf("Expected the unsaved project to be missing")
+	}
+	if removed := loaded.RemoveMissing(); removed != 1 || len(loaded.Projects) != 1 {
+		t.Errorf("Expected the missing project to be removed, got %d %v", removed, loaded.Projects)
+	}
+	loaded.Remove(uk.Path())
+	if len(loaded.Projects) != 0 {
+		t.Errorf("Expected an empty list, got %v", loaded.Projects)
+	}
+}
@@ -0,0 +1,96 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"example.com/main/translator"
+)
+
+// RecoveryDraft is an edited dictionary entry that was not saved yet
+type RecoveryDraft struct {
+	Category string                 `json:"category"`
+	Key      string                 `json:"key"` // name|source of the source entity
+	Entry    map[string]interface{} `json:"entry"`
+}
+
+// Recovery holds the unsaved edits of a project so they survive a crash.
+// The file only exists while there are unsaved edits.
+type Recovery struct {
+	Updated time.Time       `json:"updated"`
+	Drafts  []RecoveryDraft `json:"drafts"`
+
+	path string
+}
+
+// RecoveryPath returns the location of the project's recovery file
+func (p *Project) RecoveryPath() string {
+	return p.Path() + ".recovery"
+}
+
+// LoadRecovery reads a recovery file, returning no drafts when it does not exist
+func LoadRecovery(path string) (*Recovery, error) {
+	recovery := &Recovery{path: path}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return recovery, nil
+	}
+	if err != nil {
+		return recovery, fmt.Errorf("failed to read recovery file %s: %w", path, err)
+	}
+
+	err = json.Unmarshal(data, recovery)
+	if err != nil {
+		return &Recovery{path: path}, fmt.Errorf("failed to unmarshal recovery file %s: %w", path, err)
+	}
+	return recovery, nil
+}
+
+// Set records the draft of an entity, replacing an older one
+func (r *Recovery) Set(category, key string, entry map[string]interface{}, updated time.Time) {
+	r.Updated = updated
+	for i, draft := range r.Drafts {
+		if draft.Category == category && draft.Key == key {
+			r.Drafts[i].Entry = entry
+			return
+		}
+	}
+	r.Drafts = append(r.Drafts, RecoveryDraft{Category: category, Key: key, Entry: entry})
+}
+
+// Remove drops the draft of an entity once it is saved
+func (r *Recovery) Remove(category, key string) {
+	for i, draft := range r.Drafts {
+		if draft.Category == category && draft.Key == key {
+			r.Drafts = append(r.Drafts[:i], r.Drafts[i+1:]...)
+			return
+		}
+	}
+}
+
+// Save writes the drafts to the recovery file, or deletes the file when
+// there are none left
+func (r *Recovery) Save() error {
+	if len(r.Drafts) == 0 {
+		err := os.Remove(r.path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove recovery file %s: %w", r.path, err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recovery file: %w", err)
+	}
+
+	err = translator.WriteFileAtomic(r.path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write recovery file %s: %w", r.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecovery(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_recovery")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	p := New(filepath.Join(tempDir, "uk"+Extension))
+	recovery, err := LoadRecovery(p.RecoveryPath())
+	if err != nil || len(recovery.Drafts) != 0 {
+		t.Fatalf("Expected no drafts without a recovery file, got %v (%v)", recovery.Drafts, err)
+	}
+
+	updated := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	recovery.Set("background", "Acolyte|XPHB", map[string]interface{}{"name": "Аколіт"}, updated)
+	recovery.Set("background", "Sage|XPHB", map[string]interface{}{"name": "Мудр"}, updated)
+	recovery.Set("background", "Sage|XPHB", map[string]interface{}{"name": "Мудрець"}, updated)
+	if err := recovery.Save(); err != nil {
+		t.Fatalf("Failed to save recovery file: %v", err)
+	}
+
+	loaded, err := LoadRecovery(p.RecoveryPath())
+	if err != nil {
+		t.Fatalf("Failed to load recovery file: %v", err)
+	}
+	if len(loaded.Drafts) != 2 || loaded.Drafts[1].Entry["name"] != "Мудрець" || !loaded.Updated.Equal(updated) {
+		t.Errorf("Expected both drafts with the latest Sage edit, got %+v", loaded)
+	}
+
+	// Saving without drafts deletes the file
+	loaded.Remove("background", "Acolyte|XPHB")
+	loaded.Remove("background", "Sage|XPHB")
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("Failed to save empty recovery: %v", err)
+	}
+	if _, err := os.Stat(p.RecoveryPath()); !os.IsNotExist(err) {
+		t.Errorf("Expected the recovery file to be removed")
+	}
+}
@@ -0,0 +1,284 @@
+// Package render turns 5etools entry trees into HTML and Markdown
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"example.com/main/translator"
+)
+
+// HTML renders a 5etools entry tree (a string, an entry object or a list of them) as HTML
+func HTML(entries interface{}) string {
+	var b strings.Builder
+	writeHTML(&b, entries, 1)
+	return b.String()
+}
+
+// InlineHTML renders the inline tags of a single string as HTML
+func InlineHTML(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, tag := range translator.ParseTags(text) {
+		b.WriteString(html.EscapeString(text[last:tag.Start]))
+		b.WriteString(inlineTagHTML(tag))
+		last = tag.End
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// inlineTagHTML renders a single inline tag
+func inlineTagHTML(tag translator.Tag) string {
+	text := strings.Join(tag.Parts, "|")
+	switch tag.Name {
+	case "b", "bold":
+		return "<strong>" + InlineHTML(text) + "</strong>"
+	case "i", "italic":
+		return "<em>" + InlineHTML(text) + "</em>"
+	case "s", "strike":
+		return "<s>" + InlineHTML(text) + "</s>"
+	case "u", "underline":
+		return "<u>" + InlineHTML(text) + "</u>"
+	case "code":
+		return "<code>" + html.EscapeString(text) + "</code>"
+	case "note":
+		return `<span class="note">` + InlineHTML(text) + "</span>"
+	}
+	return html.EscapeString(TagText(tag))
+}
+
+// TagText returns the plain text 5etools displays for an inline tag
+func TagText(tag translator.Tag) string {
+	first := ""
+	if len(tag.Parts) > 0 {
+		first = tag.Parts[0]
+	}
+
+	switch tag.Name {
+	case "hit":
+		if strings.HasPrefix(first, "-") || strings.HasPrefix(first, "+") {
+			return first
+		}
+		return "+" + first
+	case "dc":
+		return "DC " + first
+	case "recharge":
+		if first == "" {
+			return "(Recharge 6)"
+		}
+		return fmt.Sprintf("(Recharge %s–6)", first)
+	case "dice", "damage", "d20", "chance":
+		if len(tag.Parts) > 1 && tag.Parts[1] != "" {
+			return tag.Parts[1]
+		}
+		return first
+	case "scaledice", "scaledamage":
+		if len(tag.Parts) == 0 {
+			return ""
+		}
+		return tag.Parts[len(tag.Parts)-1]
+	}
+
+	// Link tags show the display text, falling back to the linked name
+	return plainInline(tag.Display())
+}
+
+// plainInline strips inline tags from text, keeping what they display
+func plainInline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, tag := range translator.ParseTags(text) {
+		b.WriteString(text[last:tag.Start])
+		b.WriteString(TagText(tag))
+		last = tag.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// writeHTML renders an entry at the given heading depth
+func writeHTML(b *strings.Builder, entry interface{}, depth int) {
+	switch v := entry.(type) {
+	case string:
+		b.WriteString("<p>" + InlineHTML(v) + "</p>")
+	case []interface{}:
+		for _, item := range v {
+			writeHTML(b, item, depth)
+		}
+	case map[string]interface{}:
+		writeObjectHTML(b, v, depth)
+	}
+}
+
+// entryType returns the type of an entry object, inferring it for the
+// untyped objects dictionaries contain
+func entryType(entry map[string]interface{}) string {
+	if entryType, ok := entry["type"].(string); ok {
+		return entryType
+	}
+	if _, ok := entry["items"]; ok {
+		return "list"
+	}
+	if _, ok := entry["entry"]; ok {
+		return "item"
+	}
+	if _, ok := entry["rows"]; ok {
+		return "table"
+	}
+	return "entries"
+}
+
+// writeObjectHTML renders an entry object
+func writeObjectHTML(b *strings.Builder, entry map[string]interface{}, depth int) {
+	name, _ := entry["name"].(string)
+
+	switch entryType(entry) {
+	case "list":
+		b.WriteString("<ul>")
+		items, _ := entry["items"].([]interface{})
+		for _, item := range items {
+			b.WriteString("<li>")
+			if itemMap, ok := item.(map[string]interface{}); ok && entryType(itemMap) == "item" {
+				writeItemHTML(b, itemMap, depth)
+			} else if text, ok := item.(string); ok {
+				b.WriteString(InlineHTML(text))
+			} else {
+				writeHTML(b, item, depth+1)
+			}
+			b.WriteString("</li>")
+		}
+		b.WriteString("</ul>")
+	case "item", "itemSub", "itemSpell":
+		// Item entries can hold lists and tables, which a paragraph cannot
+		b.WriteString(`<div class="item">`)
+		writeItemHTML(b, entry, depth)
+		b.WriteString("</div>")
+	case "table":
+		writeTableHTML(b, entry)
+	case "inset", "insetReadaloud":
+		b.WriteString(`<aside class="` + html.EscapeString(entryType(entry)) + `">`)
+		if name != "" {
+			b.WriteString(fmt.Sprintf("<h%d>%s</h%d>", headingLevel(depth), InlineHTML(name), headingLevel(depth)))
+		}
+		writeHTML(b, entry["entries"], depth+1)
+		b.WriteString("</aside>")
+	case "quote":
+		b.WriteString("<blockquote>")
+		writeHTML(b, entry["entries"], depth)
+		by, _ := entry["by"].(string)
+		from, _ := entry["from"].(string)
+		if by != "" || from != "" {
+			b.WriteString("<footer>— " + InlineHTML(by))
+			if from != "" {
+				if by != "" {
+					b.WriteString(", ")
+				}
+				b.WriteString("<cite>" + InlineHTML(from) + "</cite>")
+			}
+			b.WriteString("</footer>")
+		}
+		b.WriteString("</blockquote>")
+	case "inline", "inlineBlock":
+		items, _ := entry["entries"].([]interface{})
+		for _, item := range items {
+			if text, ok := item.(string); ok {
+				b.WriteString(InlineHTML(text))
+			} else {
+				writeHTML(b, item, depth)
+			}
+		}
+	default:
+		if name != "" {
+			b.WriteString(fmt.Sprintf("<h%d>%s</h%d>", headingLevel(depth), InlineHTML(name), headingLevel(depth)))
+		}
+		writeHTML(b, entry["entries"], depth+1)
+	}
+}
+
+// writeItemHTML renders the content of a list item with an optional bold name
+func writeItemHTML(b *strings.Builder, item map[string]interface{}, depth int) {
+	if name, ok := item["name"].(string); ok && name != "" {
+		b.WriteString("<strong>" + InlineHTML(name) + "</strong> ")
+	}
+	if text, ok := item["entry"].(string); ok {
+		b.WriteString(InlineHTML(text))
+	}
+	if entries, ok := item["entries"].([]interface{}); ok {
+		for i, child := range entries {
+			if text, ok := child.(string); ok {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				b.WriteString(InlineHTML(text))
+			} else {
+				writeHTML(b, child, depth+1)
+			}
+		}
+	}
+}
+
+// writeTableHTML renders a table entry
+func writeTableHTML(b *strings.Builder, table map[string]interface{}) {
+	b.WriteString("<table>")
+	if caption, ok := table["caption"].(string); ok && caption != "" {
+		b.WriteString("<caption>" + InlineHTML(caption) + "</caption>")
+	}
+	if labels, ok := table["colLabels"].([]interface{}); ok && len(labels) > 0 {
+		b.WriteString("<thead><tr>")
+		for _, label := range labels {
+			b.WriteString("<th>" + InlineHTML(fmt.Sprint(label)) + "</th>")
+		}
+		b.WriteString("</tr></thead>")
+	}
+	b.WriteString("<tbody>")
+	rows, _ := table["rows"].([]interface{})
+	for _, row := range rows {
+		b.WriteString("<tr>")
+		for _, cell := range tableRowCells(row) {
+			b.WriteString("<td>" + cellHTML(cell) + "</td>")
+		}
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</tbody></table>")
+}
+
+// tableRowCells returns the cells of a table row in either the plain or the row object format
+func tableRowCells(row interface{}) []interface{} {
+	switch v := row.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		cells, _ := v["row"].([]interface{})
+		return cells
+	}
+	return nil
+}
+
+// cellHTML renders a table cell without wrapping plain text in a paragraph
+func cellHTML(cell interface{}) string {
+	switch v := cell.(type) {
+	case string:
+		return InlineHTML(v)
+	case float64:
+		return fmt.Sprint(v)
+	case map[string]interface{}:
+		if roll, ok := v["roll"].(map[string]interface{}); ok {
+			if exact, ok := roll["exact"]; ok {
+				return fmt.Sprint(exact)
+			}
+			return fmt.Sprintf("%v–%v", roll["min"], roll["max"])
+		}
+	}
+	return HTML(cell)
+}
+
+// headingLevel maps an entry depth to an HTML heading level
+func headingLevel(depth int) int {
+	level := depth + 2
+	if level > 6 {
+		level = 6
+	}
+	return level
+}
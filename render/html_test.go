@@ -0,0 +1,106 @@
+package render
+
+import (
+	"testing"
+)
+
+func TestInlineHTML(t *testing.T) {
+	html := InlineHTML("Choose {@item Book|XPHB|Book (prayers)}, {@b bold} & {@dc 15}")
+	expected := "Choose Book (prayers), <strong>bold</strong> &amp; DC 15"
+	if html != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, html)
+	}
+}
+
+func TestHTMLList(t *testing.T) {
+	entries := []interface{}{
+		map[string]interface{}{
+			"type": "list",
+			"items": []interface{}{
+				map[string]interface{}{
+					"type":  "item",
+					"name":  "Feat:",
+					"entry": "{@feat Magic Initiate|XPHB} (Cleric)",
+				},
+			},
+		},
+	}
+
+	html := HTML(entries)
+	expected := "<ul><li><strong>Feat:</strong> Magic Initiate (Cleric)</li></ul>"
+	if html != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, html)
+	}
+}
+
+func TestHTMLUntypedDictionaryList(t *testing.T) {
+	entries := []interface{}{
+		map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"name": "Риси:", "entry": "Клірик"},
+			},
+		},
+	}
+
+	html := HTML(entries)
+	expected := "<ul><li><strong>Риси:</strong> Клірик</li></ul>"
+	if html != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, html)
+	}
+}
+
+func TestHTMLItemWithBlocks(t *testing.T) {
+	entries := []interface{}{
+		map[string]interface{}{
+			"type": "item",
+			"name": "Tools:",
+			"entries": []interface{}{
+				"Choose one:",
+				map[string]interface{}{"type": "list", "items": []interface{}{"Dice", "Cards"}},
+			},
+		},
+	}
+
+	html := HTML(entries)
+	expected := `<div class="item"><strong>Tools:</strong> Choose one:<ul><li>Dice</li><li>Cards</li></ul></div>`
+	if html != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, html)
+	}
+}
+
+func TestHTMLBlocks(t *testing.T) {
+	entries := []interface{}{
+		map[string]interface{}{
+			"type":    "entries",
+			"name":    "Feature",
+			"entries": []interface{}{"Text."},
+		},
+		map[string]interface{}{
+			"type":      "table",
+			"colLabels": []interface{}{"d6", "Trinket"},
+			"rows": []interface{}{
+				[]interface{}{"1", "A {@i small} box"},
+			},
+		},
+		map[string]interface{}{
+			"type":    "inset",
+			"name":    "Note",
+			"entries": []interface{}{"Inset text."},
+		},
+		map[string]interface{}{
+			"type":    "quote",
+			"entries": []interface{}{"Words."},
+			"by":      "Someone",
+			"from":    "Somewhere",
+		},
+	}
+
+	html := HTML(entries)
+	expected := "<h3>Feature</h3><p>Text.</p>" +
+		"<table><thead><tr><th>d6</th><th>Trinket</th></tr></thead><tbody><tr><td>1</td><td>A <em>small</em> box</td></tr></tbody></table>" +
+		`<aside class="inset"><h3>Note</h3><p>Inset text.</p></aside>` +
+		"<blockquote><p>Words.</p><footer>— Someone, <cite>Somewhere</cite></footer></blockquote>"
+	if html != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, html)
+	}
+}
@@ -0,0 +1,228 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/main/translator"
+)
+
+// markdownEscaper escapes the characters Markdown would otherwise interpret
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "|", `\|`)
+
+// Markdown renders a 5etools entry tree (a string, an entry object or a list of them) as Markdown
+func Markdown(entries interface{}) string {
+	var blocks []string
+	markdownBlocks(&blocks, entries, 1)
+	return strings.Join(blocks, "\n\n")
+}
+
+// InlineMarkdown renders the inline tags of a single string as Markdown
+func InlineMarkdown(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, tag := range translator.ParseTags(text) {
+		b.WriteString(markdownEscaper.Replace(text[last:tag.Start]))
+		b.WriteString(inlineTagMarkdown(tag))
+		last = tag.End
+	}
+	b.WriteString(markdownEscaper.Replace(text[last:]))
+	return b.String()
+}
+
+// inlineTagMarkdown renders a single inline tag
+func inlineTagMarkdown(tag translator.Tag) string {
+	text := strings.Join(tag.Parts, "|")
+	switch tag.Name {
+	case "b", "bold":
+		return "**" + InlineMarkdown(text) + "**"
+	case "i", "italic", "note":
+		return "*" + InlineMarkdown(text) + "*"
+	case "s", "strike":
+		return "~~" + InlineMarkdown(text) + "~~"
+	case "u", "underline":
+		return InlineMarkdown(text)
+	case "code":
+		return "`" + text + "`"
+	}
+	return markdownEscaper.Replace(TagText(tag))
+}
+
+// markdownBlocks appends the Markdown blocks of an entry at the given heading depth
+func markdownBlocks(blocks *[]string, entry interface{}, depth int) {
+	switch v := entry.(type) {
+	case string:
+		*blocks = append(*blocks, InlineMarkdown(v))
+	case []interface{}:
+		for _, item := range v {
+			markdownBlocks(blocks, item, depth)
+		}
+	case map[string]interface{}:
+		markdownObjectBlocks(blocks, v, depth)
+	}
+}
+
+// markdownObjectBlocks appends the Markdown blocks of an entry object
+func markdownObjectBlocks(blocks *[]string, entry map[string]interface{}, depth int) {
+	name, _ := entry["name"].(string)
+
+	switch entryType(entry) {
+	case "list":
+		var lines []string
+		items, _ := entry["items"].([]interface{})
+		for _, item := range items {
+			var text string
+			if itemMap, ok := item.(map[string]interface{}); ok && entryType(itemMap) == "item" {
+				text = itemMarkdown(itemMap, depth)
+			} else {
+				text = Markdown(item)
+			}
+			lines = append(lines, "- "+indentLines(text, "  "))
+		}
+		*blocks = append(*blocks, strings.Join(lines, "\n"))
+	case "item", "itemSub", "itemSpell":
+		*blocks = append(*blocks, itemMarkdown(entry, depth))
+	case "table":
+		*blocks = append(*blocks, tableMarkdown(entry))
+	case "inset", "insetReadaloud":
+		var inner []string
+		if name != "" {
+			inner = append(inner, strings.Repeat("#", headingLevel(depth))+" "+InlineMarkdown(name))
+		}
+		markdownBlocks(&inner, entry["entries"], depth+1)
+		*blocks = append(*blocks, quoteLines(strings.Join(inner, "\n\n")))
+	case "quote":
+		var inner []string
+		markdownBlocks(&inner, entry["entries"], depth)
+		by, _ := entry["by"].(string)
+		from, _ := entry["from"].(string)
+		if by != "" || from != "" {
+			attribution := "— " + InlineMarkdown(by)
+			if from != "" {
+				if by != "" {
+					attribution += ", "
+				}
+				attribution += "*" + InlineMarkdown(from) + "*"
+			}
+			inner = append(inner, attribution)
+		}
+		*blocks = append(*blocks, quoteLines(strings.Join(inner, "\n\n")))
+	case "inline", "inlineBlock":
+		var b strings.Builder
+		items, _ := entry["entries"].([]interface{})
+		for _, item := range items {
+			if text, ok := item.(string); ok {
+				b.WriteString(InlineMarkdown(text))
+			} else {
+				b.WriteString(Markdown(item))
+			}
+		}
+		*blocks = append(*blocks, b.String())
+	default:
+		if name != "" {
+			*blocks = append(*blocks, strings.Repeat("#", headingLevel(depth))+" "+InlineMarkdown(name))
+		}
+		markdownBlocks(blocks, entry["entries"], depth+1)
+	}
+}
+
+// itemMarkdown renders the content of a list item with an optional bold name
+func itemMarkdown(item map[string]interface{}, depth int) string {
+	var parts []string
+	if name, ok := item["name"].(string); ok && name != "" {
+		parts = append(parts, "**"+InlineMarkdown(name)+"**")
+	}
+	if text, ok := item["entry"].(string); ok {
+		parts = append(parts, InlineMarkdown(text))
+	}
+	text := strings.Join(parts, " ")
+
+	if entries, ok := item["entries"].([]interface{}); ok {
+		for _, child := range entries {
+			if childText, ok := child.(string); ok {
+				if text != "" {
+					text += " "
+				}
+				text += InlineMarkdown(childText)
+			} else {
+				var blocks []string
+				markdownBlocks(&blocks, child, depth+1)
+				text += "\n\n" + strings.Join(blocks, "\n\n")
+			}
+		}
+	}
+	return text
+}
+
+// tableMarkdown renders a table entry as a pipe table
+func tableMarkdown(table map[string]interface{}) string {
+	var lines []string
+	if caption, ok := table["caption"].(string); ok && caption != "" {
+		lines = append(lines, "**"+InlineMarkdown(caption)+"**", "")
+	}
+
+	rows, _ := table["rows"].([]interface{})
+	labels, _ := table["colLabels"].([]interface{})
+	columns := len(labels)
+	for _, row := range rows {
+		if cells := tableRowCells(row); len(cells) > columns {
+			columns = len(cells)
+		}
+	}
+
+	header := make([]string, columns)
+	separator := make([]string, columns)
+	for i := range header {
+		if i < len(labels) {
+			header[i] = InlineMarkdown(fmt.Sprint(labels[i]))
+		}
+		separator[i] = "---"
+	}
+	lines = append(lines, "| "+strings.Join(header, " | ")+" |", "| "+strings.Join(separator, " | ")+" |")
+
+	for _, row := range rows {
+		cells := make([]string, columns)
+		for i, cell := range tableRowCells(row) {
+			cells[i] = strings.ReplaceAll(cellMarkdown(cell), "\n", " ")
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// cellMarkdown renders a table cell
+func cellMarkdown(cell interface{}) string {
+	switch v := cell.(type) {
+	case string:
+		return InlineMarkdown(v)
+	case float64:
+		return fmt.Sprint(v)
+	case map[string]interface{}:
+		if roll, ok := v["roll"].(map[string]interface{}); ok {
+			if exact, ok := roll["exact"]; ok {
+				return fmt.Sprint(exact)
+			}
+			return fmt.Sprintf("%v–%v", roll["min"], roll["max"])
+		}
+	}
+	return Markdown(cell)
+}
+
+// quoteLines prefixes every line of text with a blockquote marker
+func quoteLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indentLines indents every line of text but the first
+func indentLines(text, indent string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+indent)
+}
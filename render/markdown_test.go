@@ -0,0 +1,60 @@
+package render
+
+import (
+	"testing"
+)
+
+func TestInlineMarkdown(t *testing.T) {
+	markdown := InlineMarkdown("Choose {@item Book|XPHB|Book (prayers)}, {@b bold}, {@i 2*3} & {@dc 15}")
+	expected := `Choose Book (prayers), **bold**, *2\*3* & DC 15`
+	if markdown != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, markdown)
+	}
+}
+
+func TestMarkdownEntries(t *testing.T) {
+	entries := []interface{}{
+		map[string]interface{}{
+			"type": "entries",
+			"name": "Feature: Shelter of the Faithful",
+			"entries": []interface{}{
+				"You command the respect of those who share your faith.",
+				map[string]interface{}{
+					"type": "list",
+					"items": []interface{}{
+						map[string]interface{}{"type": "item", "name": "Feat:", "entry": "{@feat Magic Initiate|XPHB} (Cleric)"},
+						"Holy symbol",
+					},
+				},
+			},
+		},
+		map[string]interface{}{
+			"type":    "inset",
+			"name":    "Variant",
+			"entries": []interface{}{"Line one.", "Line two."},
+		},
+		map[string]interface{}{
+			"type":      "table",
+			"colLabels": []interface{}{"{@dice d6}", "Trait"},
+			"rows": []interface{}{
+				[]interface{}{"1", "Calm | kind"},
+			},
+		},
+		map[string]interface{}{
+			"type":    "quote",
+			"entries": []interface{}{"Knowledge is power."},
+			"by":      "Sage",
+		},
+	}
+
+	markdown := Markdown(entries)
+	expected := "### Feature: Shelter of the Faithful\n\n" +
+		"You command the respect of those who share your faith.\n\n" +
+		"- **Feat:** Magic Initiate (Cleric)\n- Holy symbol\n\n" +
+		"> ### Variant\n>\n> Line one.\n>\n> Line two.\n\n" +
+		"| d6 | Trait |\n| --- | --- |\n| 1 | Calm \\| kind |\n\n" +
+		"> Knowledge is power.\n>\n> — Sage"
+	if markdown != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, markdown)
+	}
+}
@@ -0,0 +1,36 @@
+package translator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_atomic")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "dictionary.json")
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFileAtomic(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", content, err)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil || string(data) != content {
+			t.Errorf("Expected %q, got %q (%v)", content, data, err)
+		}
+	}
+
+	files, _ := ioutil.ReadDir(tempDir)
+	if len(files) != 1 {
+		t.Errorf("Expected no temporary files to be left, got %d files", len(files))
+	}
+
+	if err := WriteFileAtomic(filepath.Join(tempDir, "missing", "file.json"), []byte("x"), 0644); err == nil {
+		t.Errorf("Expected writing into a missing directory to fail")
+	}
+}
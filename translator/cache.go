@@ -0,0 +1,233 @@
+package translator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Version is the translator version; changing it invalidates build caches
+const Version = "0.5.1"
+
+// cacheFileName is the build cache file kept in the export directory
+const cacheFileName = ".translator-cache.json"
+
+// fileCacheEntry records how an output file was built
+type fileCacheEntry struct {
+	InputHash  string   `json:"inputHash"`
+	OutputHash string   `json:"outputHash"`
+	Sources    int      `json:"sources"`
+	Matched    []string `json:"matched,omitempty"`
+	Translated []string `json:"translated,omitempty"`
+	Issues     []Issue  `json:"issues,omitempty"`
+}
+
+// entityCacheEntry records the merged output of a single entity. Entities of
+// streamed files only keep their input hash; their output is copied from the
+// previous export file, so the cache does not grow with the size of the data.
+type entityCacheEntry struct {
+	InputHash string          `json:"inputHash"`
+	Output    json.RawMessage `json:"output,omitempty"`
+}
+
+// buildCache is the on-disk cache used to skip unchanged work
+type buildCache struct {
+	Version  string                      `json:"version"`
+	Files    map[string]fileCacheEntry   `json:"files"`
+	Entities map[string]entityCacheEntry `json:"entities"`
+
+	mu sync.Mutex // guards Files and Entities while workers run
+}
+
+func newBuildCache() *buildCache {
+	return &buildCache{
+		Version:  Version,
+		Files:    make(map[string]fileCacheEntry),
+		Entities: make(map[string]entityCacheEntry),
+	}
+}
+
+// loadCache reads the build cache from the export directory,
+// returning an empty cache when it is missing, outdated or forced off
+func (t *Translator) loadCache() *buildCache {
+	if t.options.Force {
+		return newBuildCache()
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(t.exportPath, cacheFileName))
+	if err != nil {
+		return newBuildCache()
+	}
+
+	cache := newBuildCache()
+	err = json.Unmarshal(data, cache)
+	if err != nil || cache.Version != Version {
+		fmt.Printf("Ignoring outdated build cache\n")
+		return newBuildCache()
+	}
+	if cache.Files == nil {
+		cache.Files = make(map[string]fileCacheEntry)
+	}
+	if cache.Entities == nil {
+		cache.Entities = make(map[string]entityCacheEntry)
+	}
+
+	return cache
+}
+
+// saveCache writes the build cache to the export directory
+func (t *Translator) saveCache(cache *buildCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to marshal build cache: %w", err)
+	}
+
+	err = os.MkdirAll(t.exportPath, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(t.exportPath, cacheFileName), data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write build cache: %w", err)
+	}
+
+	return nil
+}
+
+// file returns the cache entry of an output file
+func (c *buildCache) file(file string) (fileCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.Files[file]
+	return entry, ok
+}
+
+// setFile stores the cache entry of an output file
+func (c *buildCache) setFile(file string, entry fileCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Files[file] = entry
+}
+
+// entity returns the cached output of an entity
+func (c *buildCache) entity(key string) (entityCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.Entities[key]
+	return entry, ok
+}
+
+// setEntity stores the cached output of an entity
+func (c *buildCache) setEntity(key string, entry entityCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Entities[key] = entry
+}
+
+// keepEntities copies the cached entities of a category into another cache
+func (c *buildCache) keepEntities(category string, keys []string, into *buildCache) {
+	for _, key := range keys {
+		if entry, ok := c.entity(category + "|" + key); ok {
+			into.setEntity(category+"|"+key, entry)
+		}
+	}
+}
+
+// hashStrings returns a short hash of the given parts
+func hashStrings(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// hashFile returns the content hash of a file, the same as hashStrings of
+// its content, without reading the whole file into memory
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	hash.Write([]byte{0})
+	return hex.EncodeToString(hash.Sum(nil)[:16]), nil
+}
+
+// hashValue returns the hash of a decoded JSON value
+func hashValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return hashStrings(string(data))
+}
+
+// dictionaryHash returns a combined content hash of all dictionary files,
+// taken layer by layer in the order the fallback chain reads them
+func (t *Translator) dictionaryHash() (string, error) {
+	parts := []string{}
+	for _, layer := range t.layers() {
+		parts = append(parts, layer.name)
+		for _, dictionaryPath := range layer.paths {
+			files, err := filepath.Glob(filepath.Join(dictionaryPath, "*.json"))
+			if err != nil {
+				return "", fmt.Errorf("failed to glob dictionary files: %w", err)
+			}
+			for _, file := range files {
+				fileHash, err := hashFile(file)
+				if err != nil {
+					return "", fmt.Errorf("failed to hash dictionary file %s: %w", file, err)
+				}
+				parts = append(parts, file, fileHash)
+			}
+		}
+	}
+
+	return hashStrings(parts...), nil
+}
+
+// optionsHash returns the hash of the options that influence the output,
+// together with the locale and the names of its fallback layers
+func (t *Translator) optionsHash() string {
+	options := t.options
+	options.Force = false
+	options.Jobs = 0
+	options.StreamThreshold = 0
+	// Strict options only decide whether the recorded issues fail the run
+	options.FailOnInvalid = false
+	options.FailOnOrphans = false
+	options.FailOnStale = false
+	options.FailOnTagMismatch = false
+	options.FailOnCrossReference = false
+
+	parts := []string{hashValue(options), t.locale}
+	for _, layer := range t.layers() {
+		parts = append(parts, layer.name)
+	}
+	return hashStrings(parts...)
+}
+
+// cloneValue returns a deep copy of a decoded JSON value
+func cloneValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var clone interface{}
+	err = json.Unmarshal(data, &clone)
+	return clone, err
+}
@@ -0,0 +1,166 @@
+package translator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeJSONFile(t *testing.T, path string, value interface{}) {
+	data, err := json.MarshalIndent(value, "", "    ")
+	if err != nil {
+		t.Fatalf("Failed to marshal %s: %v", path, err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", path, err)
+	}
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestTranslateUsesBuildCache(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_cache")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+	exportDir := filepath.Join(tempDir, "export")
+
+	writeJSONFile(t, filepath.Join(dataDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Acolyte", "source": "XPHB", "page": 178},
+			map[string]interface{}{"name": "Sage", "source": "XPHB", "page": 183},
+		},
+	})
+	dictionary := map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"origin_name": "Acolyte", "origin_source": "XPHB", "name": "Аколіт"},
+			map[string]interface{}{"origin_name": "Sage", "origin_source": "XPHB", "name": "Мудрець"},
+		},
+	}
+	writeJSONFile(t, filepath.Join(dictDir, "backgrounds.json"), dictionary)
+
+	translator := NewTranslator(dataDir, dictDir, exportDir)
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	if len(translator.Skipped()) != 0 {
+		t.Errorf("Expected nothing to be skipped on the first run, got %v", translator.Skipped())
+	}
+
+	// Unchanged inputs reuse the previous output
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	if len(translator.Skipped()) != 1 {
+		t.Errorf("Expected backgrounds.json to be skipped, got %v", translator.Skipped())
+	}
+
+	stats := translator.Stats()
+	if stats.SkippedFiles != 1 || stats.SourceEntities != 2 || stats.Translated != 2 || stats.Coverage() != 100 {
+		t.Errorf("Expected stats of the cached file, got %+v", stats)
+	}
+
+	// Strict options do not change the output
+	translator.SetOptions(StrictOptions())
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	if len(translator.Skipped()) != 1 {
+		t.Errorf("Expected strict options to reuse the output, got skipped %v", translator.Skipped())
+	}
+	translator.SetOptions(Options{})
+
+	// A dictionary change rebuilds the file but reuses unchanged entities
+	dictionary["background"].([]interface{})[1].(map[string]interface{})["name"] = "Мудрець [Sage]"
+	writeJSONFile(t, filepath.Join(dictDir, "backgrounds.json"), dictionary)
+
+	cache := translator.loadCache()
+	acolyte := cache.Entities["background|Acolyte|XPHB"]
+	sage := cache.Entities["background|Sage|XPHB"]
+
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	if len(translator.Skipped()) != 0 {
+		t.Errorf("Expected changed dictionary to rebuild, got skipped %v", translator.Skipped())
+	}
+
+	cache = translator.loadCache()
+	if cache.Entities["background|Acolyte|XPHB"].InputHash != acolyte.InputHash {
+		t.Errorf("Expected unchanged Acolyte entry to keep its cache entry")
+	}
+	if cache.Entities["background|Sage|XPHB"].InputHash == sage.InputHash {
+		t.Errorf("Expected changed Sage entry to be re-merged")
+	}
+
+	// Force ignores the cache
+	translator.SetOptions(Options{Force: true})
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	if len(translator.Skipped()) != 0 {
+		t.Errorf("Expected force to rebuild everything, got skipped %v", translator.Skipped())
+	}
+}
+
+func TestBuildCacheFollowsFallbackOrder(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_cache_fallback")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	ownDir := filepath.Join(tempDir, "dictionary", "uk-x-homebrew")
+	ukDir := filepath.Join(tempDir, "dictionary", "uk")
+	oldDir := filepath.Join(tempDir, "dictionary", "uk-x-old")
+	exportDir := filepath.Join(tempDir, "export")
+
+	writeJSONFile(t, filepath.Join(dataDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{map[string]interface{}{"name": "Sage", "source": "XPHB"}},
+	})
+	writeJSONFile(t, filepath.Join(ownDir, "backgrounds.json"), map[string]interface{}{})
+	writeJSONFile(t, filepath.Join(ukDir, "backgrounds.json"), map[string]interface{}{
+		"background": map[string]interface{}{"origin_name": "Sage", "origin_source": "XPHB", "name": "Мудрець"},
+	})
+	writeJSONFile(t, filepath.Join(oldDir, "backgrounds.json"), map[string]interface{}{
+		"background": map[string]interface{}{"origin_name": "Sage", "origin_source": "XPHB", "name": "Книжник"},
+	})
+
+	build := func(fallbacks ...string) string {
+		translator := NewTranslator(dataDir, ownDir, exportDir)
+		translator.SetLocale("uk-x-homebrew")
+		for _, fallback := range fallbacks {
+			translator.AddFallback(fallback, filepath.Join(tempDir, "dictionary", fallback))
+		}
+		if err := translator.Translate(); err != nil {
+			t.Fatalf("Failed to translate: %v", err)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(exportDir, "backgrounds.json"))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		var output map[string][]map[string]interface{}
+		if err := json.Unmarshal(data, &output); err != nil {
+			t.Fatalf("Failed to unmarshal output: %v", err)
+		}
+		return output["background"][0]["name"].(string)
+	}
+
+	if name := build("uk", "uk-x-old"); name != "Мудрець" {
+		t.Errorf("Expected the first fallback to win, got %q", name)
+	}
+	// Reordering the chain must not reuse the cached output
+	if name := build("uk-x-old", "uk"); name != "Книжник" {
+		t.Errorf("Expected the reordered chain to rebuild, got %q", name)
+	}
+}
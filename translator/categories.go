@@ -0,0 +1,75 @@
+package translator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// Category describes a kind of 5etools entity and the data files holding it
+type Category struct {
+	Key   string   // JSON key of the entity array, e.g. "background"
+	Files string   // glob of data files relative to the data directory
+	Tags  []string // inline tags linking to entities of the category
+}
+
+// categories lists the entity categories the translator knows about
+var categories = []Category{
+	{Key: "background", Files: "backgrounds.json", Tags: []string{"background"}},
+	{Key: "feat", Files: "feats.json", Tags: []string{"feat"}},
+	{Key: "item", Files: "items.json", Tags: []string{"item"}},
+	{Key: "race", Files: "races.json", Tags: []string{"race"}},
+	{Key: "optionalfeature", Files: "optionalfeatures.json", Tags: []string{"optfeature"}},
+	{Key: "condition", Files: "conditionsdiseases.json", Tags: []string{"condition", "disease"}},
+	{Key: "spell", Files: "spells/spells-*.json", Tags: []string{"spell"}},
+	{Key: "monster", Files: "bestiary/bestiary-*.json", Tags: []string{"creature"}},
+}
+
+// Categories returns all known entity categories
+func Categories() []Category {
+	return append([]Category(nil), categories...)
+}
+
+// LookupCategory returns the category with the given key
+func LookupCategory(key string) (Category, bool) {
+	for _, category := range categories {
+		if category.Key == key {
+			return category, true
+		}
+	}
+	return Category{}, false
+}
+
+// dataFiles returns the category data files in dataPath, relative to it and sorted
+func (c Category) dataFiles(dataPath string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dataPath, c.Files))
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob %s data files: %w", c.Key, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no %s data files matching %s", c.Key, c.Files)
+	}
+
+	files := make([]string, 0, len(matches))
+	for _, match := range matches {
+		file, err := filepath.Rel(dataPath, match)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// DetectCategories returns the keys of the categories that have data files in dataPath
+func DetectCategories(dataPath string) []string {
+	var keys []string
+	for _, category := range categories {
+		if _, err := category.dataFiles(dataPath); err == nil {
+			keys = append(keys, category.Key)
+		}
+	}
+	return keys
+}
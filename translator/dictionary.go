@@ -0,0 +1,135 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// dictionaryIndex holds dictionary entries by category and name|source key.
+// It is built once per run and only read during it, so workers share it.
+type dictionaryIndex struct {
+	entries map[string]map[string]map[string]interface{}
+	keys    map[string][]string // keys of each category in reading order
+	issues  []Issue
+	origins map[string]map[string][]StringOrigin // layer of every translated string
+}
+
+func newDictionaryIndex() *dictionaryIndex {
+	return &dictionaryIndex{
+		entries: make(map[string]map[string]map[string]interface{}),
+		keys:    make(map[string][]string),
+		origins: make(map[string]map[string][]StringOrigin),
+	}
+}
+
+// add indexes a dictionary entry; later entries replace earlier ones with the same key
+func (d *dictionaryIndex) add(category string, entry map[string]interface{}) {
+	originName, originNameOk := entry["origin_name"].(string)
+	originSource, originSourceOk := entry["origin_source"].(string)
+
+	if !originNameOk || !originSourceOk {
+		d.issues = append(d.issues, Issue{Kind: IssueInvalid, Message: fmt.Sprintf("skipping %s dictionary entry without proper origin info", category)})
+		return // Skip entries without proper origin info
+	}
+
+	key := originName + "|" + originSource
+	if d.entries[category] == nil {
+		d.entries[category] = make(map[string]map[string]interface{})
+	}
+	if _, exists := d.entries[category][key]; !exists {
+		d.keys[category] = append(d.keys[category], key)
+	}
+	d.entries[category][key] = entry
+}
+
+// lookup returns the dictionary entry for a category entity key
+func (d *dictionaryIndex) lookup(category, key string) (map[string]interface{}, bool) {
+	entry, exists := d.entries[category][key]
+	return entry, exists
+}
+
+// dictionaryPaths returns the directories of all dictionary layers in reading order
+func (t *Translator) dictionaryPaths() []string {
+	var paths []string
+	for _, layer := range t.layers() {
+		paths = append(paths, layer.paths...)
+	}
+	return paths
+}
+
+// loadDictionaryIndex loads the dictionaries of every layer and combines them
+// into a single index following the fallback chain
+func (t *Translator) loadDictionaryIndex() (*dictionaryIndex, error) {
+	indexes, names, err := t.loadDictionaryLayers()
+	if err != nil {
+		return nil, err
+	}
+	return composeLayers(indexes, names), nil
+}
+
+// loadDictionaryLayers loads the index of every layer of the fallback chain
+// together with the layer names
+func (t *Translator) loadDictionaryLayers() ([]*dictionaryIndex, []string, error) {
+	var indexes []*dictionaryIndex
+	var names []string
+	for _, layer := range t.layers() {
+		index, err := loadDictionaryLayer(layer.paths)
+		if err != nil {
+			return nil, nil, err
+		}
+		indexes = append(indexes, index)
+		names = append(names, layer.name)
+	}
+	return indexes, names, nil
+}
+
+// loadDictionaryLayer loads the dictionary files of one layer and indexes their entries
+func loadDictionaryLayer(dictionaryPaths []string) (*dictionaryIndex, error) {
+	var files []string
+	for _, dictionaryPath := range dictionaryPaths {
+		matches, err := filepath.Glob(filepath.Join(dictionaryPath, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to glob dictionary files: %w", err)
+		}
+		files = append(files, matches...)
+	}
+	return loadDictionaryFiles(files)
+}
+
+// loadDictionaryFiles reads dictionary files in order and indexes their entries
+func loadDictionaryFiles(files []string) (*dictionaryIndex, error) {
+	index := newDictionaryIndex()
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dictionary file %s: %w", file, err)
+		}
+
+		var dictData map[string]interface{}
+		err = json.Unmarshal(data, &dictData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal dictionary file %s: %w", file, err)
+		}
+
+		// Extract the entries of every known category from the dictionary
+		for _, category := range categories {
+			switch v := dictData[category.Key].(type) {
+			case map[string]interface{}:
+				// Single object format
+				index.add(category.Key, v)
+			case []interface{}:
+				// Array format
+				for _, item := range v {
+					if entryMap, ok := item.(map[string]interface{}); ok {
+						index.add(category.Key, entryMap)
+					}
+				}
+			}
+		}
+	}
+
+	return index, nil
+}
@@ -0,0 +1,157 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// SaveEntry writes a dictionary entry for a source entity into the
+// translator's own dictionary. The entry replaces the one with the same
+// origin in the file that currently provides it; new entries go to the file
+// named after the entity's data file. It returns the path of the written file.
+func (t *Translator) SaveEntry(entry Entry, dictEntry map[string]interface{}) (string, error) {
+	file, err := t.dictionaryFile(entry)
+	if err != nil {
+		return "", err
+	}
+
+	dictData, err := readDictionaryFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	dictData[entry.Category] = upsertEntry(dictData[entry.Category], entry.Key, dictEntry)
+
+	err = writeDictionaryFile(file, dictData)
+	if err != nil {
+		return "", err
+	}
+
+	return file, nil
+}
+
+// dictionaryFile returns the file of the translator's own dictionary that holds
+// the entry of a source entity; the last file wins like in loadDictionaryFiles
+func (t *Translator) dictionaryFile(entry Entry) (string, error) {
+	var files []string
+	for _, dictionaryPath := range append([]string{t.dictionaryPath}, t.extraDictionaries...) {
+		matches, err := filepath.Glob(filepath.Join(dictionaryPath, "*.json"))
+		if err != nil {
+			return "", fmt.Errorf("failed to glob dictionary files: %w", err)
+		}
+		files = append(files, matches...)
+	}
+
+	for i := len(files) - 1; i >= 0; i-- {
+		index, err := loadDictionaryFiles(files[i : i+1])
+		if err != nil {
+			return "", err
+		}
+		if _, exists := index.lookup(entry.Category, entry.Key); exists {
+			return files[i], nil
+		}
+	}
+
+	return filepath.Join(t.dictionaryPath, filepath.Base(entry.File)), nil
+}
+
+// upsertEntry replaces the entry with the given origin key in a dictionary
+// category value or appends it, keeping the single object format when possible
+func upsertEntry(value interface{}, key string, dictEntry map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if entryKey(v) == key {
+			return dictEntry
+		}
+		return []interface{}{v, dictEntry}
+	case []interface{}:
+		for i, item := range v {
+			if itemMap, ok := item.(map[string]interface{}); ok && entryKey(itemMap) == key {
+				v[i] = dictEntry
+				return v
+			}
+		}
+		return append(v, dictEntry)
+	}
+	return []interface{}{dictEntry}
+}
+
+// entryKey returns the origin name|source key of a dictionary entry
+func entryKey(dictEntry map[string]interface{}) string {
+	originName, _ := dictEntry["origin_name"].(string)
+	originSource, _ := dictEntry["origin_source"].(string)
+	return originName + "|" + originSource
+}
+
+// MergeEntry returns the entry with a new entry of the translator's own dictionary applied, as the next
+// translation run would produce it
+func (t *Translator) MergeEntry(entry Entry, dictEntry map[string]interface{}) (Entry, error) {
+	entry.Primary = dictEntry
+	merged := dictEntry
+
+	// The saved entry replaces the translator's own layer; the fallbacks still
+	// complete it, and references to the entity show its new name from now on
+	if t.index != nil {
+		t.layerIndexes[0].add(entry.Category, dictEntry)
+		t.index.keys[entry.Category] = t.layerIndexes[0].keys[entry.Category]
+		t.index.compose(t.layerIndexes, t.layerNames, entry.Category, entry.Key)
+		if composed, exists := t.index.lookup(entry.Category, entry.Key); exists {
+			merged = composed
+		}
+		t.references = newReferenceIndex(t.index, t.options.NamingPattern)
+	}
+
+	entry.Dictionary = merged
+	entry.Translated = nil
+	if hasTranslation(merged) {
+		translated, err := t.mergeEntity(entry.Category+"|"+entry.Key, entry.Source, merged)
+		if err != nil {
+			return entry, fmt.Errorf("failed to merge %s %s: %w", entry.Category, entry.Key, err)
+		}
+		entry.Translated = translated
+	}
+	entry.Issues = append(checkEntry(entry.Key, entry.Source, merged), t.referenceIssues(entry.Key, merged)...)
+	return entry, nil
+}
+
+// readDictionaryFile reads a dictionary file, returning empty data when it does not exist yet
+func readDictionaryFile(file string) (map[string]interface{}, error) {
+	dictData := make(map[string]interface{})
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return dictData, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dictionary file %s: %w", file, err)
+	}
+
+	err = json.Unmarshal(data, &dictData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dictionary file %s: %w", file, err)
+	}
+	return dictData, nil
+}
+
+// writeDictionaryFile writes dictionary data to a file, creating its directory
+func writeDictionaryFile(file string, dictData map[string]interface{}) error {
+	jsonData, err := json.MarshalIndent(dictData, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal dictionary file %s: %w", file, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create dictionary directory: %w", err)
+	}
+
+	// Dictionaries hold the translators' work, so they are never left half written
+	err = WriteFileAtomic(file, jsonData, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write dictionary file %s: %w", file, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package translator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveEntry(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_save_entry")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dictDir := filepath.Join(tempDir, "dictionary")
+	writeJSONFile(t, filepath.Join(dictDir, "custom.json"), map[string]interface{}{
+		"background": map[string]interface{}{"origin_name": "Acolyte", "origin_source": "XPHB", "name": "Аколіт"},
+	})
+
+	translator := NewTranslator(filepath.Join(tempDir, "data"), dictDir, filepath.Join(tempDir, "export"))
+
+	acolyte := Entry{
+		Category: "background",
+		File:     "backgrounds.json",
+		Key:      "Acolyte|XPHB",
+		Source: map[string]interface{}{
+			"name":    "Acolyte",
+			"source":  "XPHB",
+			"entries": []interface{}{"Temple service.", map[string]interface{}{"type": "list", "items": []interface{}{"Holy symbol", "Prayer book"}}},
+		},
+	}
+	leaves := EntryLeaves(acolyte.Source)
+	if len(leaves) != 4 || leaves[3].PathString() != "name" {
+		t.Fatalf("Unexpected leaves %v", leaves)
+	}
+
+	dictEntry, err := DictionaryEntry(acolyte.Source, map[string]interface{}{"note": "keep"}, []string{"Служіння у храмі.", "Святий символ", "", "Аколіт"})
+	if err != nil {
+		t.Fatalf("Failed to build dictionary entry: %v", err)
+	}
+	if dictEntry["note"] != "keep" || dictEntry["origin_hash"] != EntityHash(acolyte.Source) {
+		t.Errorf("Expected existing fields and the origin hash, got %v", dictEntry)
+	}
+
+	// The existing entry is updated in the file that holds it
+	file, err := translator.SaveEntry(acolyte, dictEntry)
+	if err != nil {
+		t.Fatalf("Failed to save entry: %v", err)
+	}
+	if file != filepath.Join(dictDir, "custom.json") {
+		t.Errorf("Expected custom.json to be updated, got %s", file)
+	}
+
+	// New entries go to the file named after the data file
+	sage := Entry{Category: "background", File: "backgrounds.json", Key: "Sage|XPHB", Source: map[string]interface{}{"name": "Sage", "source": "XPHB"}}
+	sageEntry, err := DictionaryEntry(sage.Source, nil, []string{"Мудрець"})
+	if err != nil {
+		t.Fatalf("Failed to build dictionary entry: %v", err)
+	}
+	file, err = translator.SaveEntry(sage, sageEntry)
+	if err != nil {
+		t.Fatalf("Failed to save entry: %v", err)
+	}
+	if file != filepath.Join(dictDir, "backgrounds.json") {
+		t.Errorf("Expected backgrounds.json to be created, got %s", file)
+	}
+
+	index, err := translator.loadDictionaryIndex()
+	if err != nil {
+		t.Fatalf("Failed to load dictionary: %v", err)
+	}
+	if len(index.keys["background"]) != 2 {
+		t.Errorf("Expected 2 dictionary entries, got %v", index.keys["background"])
+	}
+	saved, _ := index.lookup("background", "Acolyte|XPHB")
+	entries := saved["entries"].([]interface{})
+	if entries[0] != "Служіння у храмі." || entries[1].(map[string]interface{})["items"].([]interface{})[0] != "Святий символ" {
+		t.Errorf("Expected the edited entries in the source shape, got %v", entries)
+	}
+
+	merged, err := translator.MergeEntry(acolyte, saved)
+	if err != nil {
+		t.Fatalf("Failed to merge entry: %v", err)
+	}
+	items := merged.Translated["entries"].([]interface{})[1].(map[string]interface{})["items"].([]interface{})
+	if items[1] != "Prayer book" {
+		t.Errorf("Expected the untranslated item to keep the source text, got %v", items[1])
+	}
+}
+
+func TestMergeEntryChecksCrossReferences(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_merge_entry_references")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+	writeJSONFile(t, filepath.Join(dataDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Acolyte", "source": "XPHB", "entries": []interface{}{"See {@background Sage|XPHB}."}},
+			map[string]interface{}{"name": "Sage", "source": "XPHB"},
+		},
+	})
+	writeJSONFile(t, filepath.Join(dictDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"origin_name": "Acolyte", "origin_source": "XPHB", "name": "Аколіт", "entries": []interface{}{"Див. {@background Sage|XPHB|Мудрець}."}},
+			map[string]interface{}{"origin_name": "Sage", "origin_source": "XPHB", "name": "Мудрець"},
+		},
+	})
+
+	translator := NewTranslator(dataDir, dictDir, filepath.Join(tempDir, "export"))
+	entries, err := translator.Entries()
+	if err != nil {
+		t.Fatalf("Failed to load entries: %v", err)
+	}
+	acolyte, sage := entries[0], entries[1]
+
+	// Renaming the referenced entity makes the existing reference stale
+	sageEntry := map[string]interface{}{"origin_name": "Sage", "origin_source": "XPHB", "name": "Мудрій"}
+	if _, err := translator.MergeEntry(sage, sageEntry); err != nil {
+		t.Fatalf("Failed to merge entry: %v", err)
+	}
+	merged, err := translator.MergeEntry(acolyte, acolyte.Dictionary)
+	if err != nil {
+		t.Fatalf("Failed to merge entry: %v", err)
+	}
+	if len(merged.Issues) != 1 || merged.Issues[0].Kind != IssueCrossReference {
+		t.Errorf("Expected a cross-reference issue after the rename, got %v", merged.Issues)
+	}
+
+	translator.SetOptions(Options{FixCrossReferences: true})
+	merged, err = translator.MergeEntry(acolyte, acolyte.Dictionary)
+	if err != nil {
+		t.Fatalf("Failed to merge entry: %v", err)
+	}
+	if len(merged.Issues) != 0 {
+		t.Errorf("Expected fixed references not to be reported, got %v", merged.Issues)
+	}
+	if text := merged.Translated["entries"].([]interface{})[0]; text != "Див. {@background Sage|XPHB|Мудрій}." {
+		t.Errorf("Expected the reference to show the new name, got %v", text)
+	}
+}
+
+func TestSaveEntryKeepsFallbackStrings(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_save_entry_fallback")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	homebrewDir := filepath.Join(tempDir, "dictionary", "uk-x-homebrew")
+	ukDir := filepath.Join(tempDir, "dictionary", "uk")
+	writeJSONFile(t, filepath.Join(dataDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Acolyte", "source": "XPHB", "entries": []interface{}{"Temple service."}},
+		},
+	})
+	writeJSONFile(t, filepath.Join(ukDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"origin_name": "Acolyte", "origin_source": "XPHB", "origin_hash": "uk-hash", "name": "Аколіт", "entries": []interface{}{"Служіння у храмі."}},
+		},
+	})
+
+	translator := NewTranslator(dataDir, homebrewDir, filepath.Join(tempDir, "export"))
+	translator.SetLocale("uk-x-homebrew")
+	translator.AddFallback("uk", ukDir)
+	entries, err := translator.Entries()
+	if err != nil {
+		t.Fatalf("Failed to load entries: %v", err)
+	}
+	acolyte := entries[0]
+	if acolyte.Primary != nil || acolyte.Dictionary["name"] != "Аколіт" {
+		t.Fatalf("Expected only the fallback entry, got %v and %v", acolyte.Primary, acolyte.Dictionary)
+	}
+	if status := acolyte.Status(); status != StatusInherited {
+		t.Errorf("Expected the fallback translation to be inherited, got %s", status)
+	}
+
+	// Only the name is edited; the entries stay empty and keep falling back
+	dictEntry, err := DictionaryEntry(acolyte.Source, acolyte.Primary, []string{"", "Послушник"})
+	if err != nil {
+		t.Fatalf("Failed to build dictionary entry: %v", err)
+	}
+	file, err := translator.SaveEntry(acolyte, dictEntry)
+	if err != nil {
+		t.Fatalf("Failed to save entry: %v", err)
+	}
+	if file != filepath.Join(homebrewDir, "backgrounds.json") {
+		t.Errorf("Expected the entry to be saved to the homebrew layer, got %s", file)
+	}
+
+	saved, err := readDictionaryFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read saved dictionary: %v", err)
+	}
+	savedEntry := saved["background"].([]interface{})[0].(map[string]interface{})
+	if _, exists := savedEntry["entries"]; exists || savedEntry["name"] != "Послушник" || savedEntry["origin_hash"] != EntityHash(acolyte.Source) {
+		t.Errorf("Expected the fallback strings not to be saved, got %v", savedEntry)
+	}
+
+	merged, err := translator.MergeEntry(acolyte, dictEntry)
+	if err != nil {
+		t.Fatalf("Failed to merge entry: %v", err)
+	}
+	if merged.Primary["name"] != "Послушник" || merged.Translated["name"] != "Послушник" {
+		t.Errorf("Expected the saved name, got %v", merged.Translated["name"])
+	}
+	if status := merged.Status(); status != StatusDraft {
+		t.Errorf("Expected the saved entry to be a draft, got %s", status)
+	}
+	if text := merged.Translated["entries"].([]interface{})[0]; text != "Служіння у храмі." {
+		t.Errorf("Expected the entries to still fall back to uk, got %v", text)
+	}
+}
@@ -0,0 +1,103 @@
+package translator
+
+import (
+	"fmt"
+)
+
+// Entry is a source entity together with its translation
+type Entry struct {
+	Category   string
+	File       string                 // data file relative to the data directory
+	Key        string                 // name|source of the source entity
+	Source     map[string]interface{} // original entity
+	Dictionary map[string]interface{} // dictionary entry completed by the fallback layers, nil when there is none
+	Primary    map[string]interface{} // entry of the translator's own dictionary, nil when only fallbacks have one
+	Translated map[string]interface{} // merged entity, nil when untranslated
+	Issues     []Issue                // QA issues of the dictionary entry
+}
+
+// Entries loads every source entity of the enabled categories and merges
+// the available translations without writing anything to the export directory.
+// Like Translate it records the issues and statistics of the run; StrictError
+// tells whether they should fail an export built from the entries. The
+// dictionary stays loaded so MergeEntry checks edits against it.
+func (t *Translator) Entries() ([]Entry, error) {
+	t.issues = nil
+	t.origins = nil
+	t.stats = Stats{}
+
+	if err := ValidateNamingPattern(t.options.NamingPattern); err != nil {
+		return nil, err
+	}
+
+	enabled, err := t.enabledCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := t.planJobs(enabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load source data: %w", err)
+	}
+
+	layerIndexes, layerNames, err := t.loadDictionaryLayers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dictionary data: %w", err)
+	}
+	index := composeLayers(layerIndexes, layerNames)
+	t.index = index
+	t.layerIndexes = layerIndexes
+	t.layerNames = layerNames
+	t.references = newReferenceIndex(index, t.options.NamingPattern)
+
+	var entries []Entry
+	for _, job := range jobs {
+		sourceData, err := t.loadSourceData(job.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load source data %s: %w", job.file, err)
+		}
+
+		entities, _ := sourceData[job.category.Key].([]interface{})
+		job.result = &mergeResult{sources: len(entities)}
+		for _, entity := range entities {
+			sourceEntity, ok := entity.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, nameOk := sourceEntity["name"].(string)
+			source, sourceOk := sourceEntity["source"].(string)
+			if !nameOk || !sourceOk {
+				continue
+			}
+
+			entry := Entry{
+				Category: job.category.Key,
+				File:     job.file,
+				Key:      name + "|" + source,
+				Source:   sourceEntity,
+			}
+
+			entry.Primary, _ = layerIndexes[0].lookup(job.category.Key, entry.Key)
+			if dictEntry, exists := index.lookup(job.category.Key, entry.Key); exists {
+				entry.Dictionary = dictEntry
+				entry.Issues = append(checkEntry(entry.Key, sourceEntity, dictEntry), t.referenceIssues(entry.Key, dictEntry)...)
+				job.result.matched = append(job.result.matched, entry.Key)
+				job.result.issues = append(job.result.issues, entry.Issues...)
+			}
+			if entry.Dictionary != nil && hasTranslation(entry.Dictionary) {
+				entry.Translated, err = t.mergeEntity(job.category.Key+"|"+entry.Key, sourceEntity, entry.Dictionary)
+				if err != nil {
+					return nil, fmt.Errorf("failed to merge %s %s: %w", job.category.Key, entry.Key, err)
+				}
+				job.result.translated = append(job.result.translated, entry.Key)
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	t.collectIssues(enabled, jobs, index)
+	t.stats = collectStats(jobs, t.issues)
+
+	return entries, nil
+}
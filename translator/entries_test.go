@@ -0,0 +1,154 @@
+package translator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_entries")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+	exportDir := filepath.Join(tempDir, "export")
+
+	writeJSONFile(t, filepath.Join(dataDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Acolyte", "source": "XPHB"},
+			map[string]interface{}{"name": "Sage", "source": "XPHB"},
+		},
+	})
+	writeJSONFile(t, filepath.Join(dictDir, "backgrounds.json"), map[string]interface{}{
+		"background": map[string]interface{}{"origin_name": "Sage", "origin_source": "XPHB", "origin_hash": "outdated", "name": "Мудрець"},
+	})
+
+	translator := NewTranslator(dataDir, dictDir, exportDir)
+	entries, err := translator.Entries()
+	if err != nil {
+		t.Fatalf("Failed to load entries: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].Key != "Acolyte|XPHB" || entries[0].Translated != nil {
+		t.Errorf("Expected untranslated Acolyte first, got %v", entries[0])
+	}
+
+	if entries[1].Translated["name"] != "Мудрець" || entries[1].Source["name"] != "Sage" {
+		t.Errorf("Expected translated Sage with its source, got %v", entries[1])
+	}
+
+	if status := entries[1].Status(); status != StatusStale {
+		t.Errorf("Expected Sage to be stale, got %s with issues %v", status, entries[1].Issues)
+	}
+
+	if _, err := os.Stat(exportDir); !os.IsNotExist(err) {
+		t.Errorf("Expected Entries not to write to the export directory")
+	}
+}
+
+func TestEntriesRecordsIssuesForStrictMode(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_entries")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+
+	writeJSONFile(t, filepath.Join(dataDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Sage", "source": "XPHB"},
+		},
+	})
+	writeJSONFile(t, filepath.Join(dictDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"origin_name": "Sage", "origin_source": "XPHB", "origin_hash": "outdated", "name": "Мудрець"},
+			map[string]interface{}{"origin_name": "Hermit", "origin_source": "XPHB", "name": "Відлюдник"},
+		},
+	})
+
+	translator := NewTranslator(dataDir, dictDir, filepath.Join(tempDir, "export"))
+	if _, err := translator.Entries(); err != nil {
+		t.Fatalf("Failed to load entries: %v", err)
+	}
+	if err := translator.StrictError(); err != nil {
+		t.Errorf("Expected no fatal issues without strict options, got %v", err)
+	}
+	if stats := translator.Stats(); stats.SourceEntities != 1 || stats.Translated != 1 || stats.Issues != 2 {
+		t.Errorf("Expected stats with a stale and an orphan issue, got %+v", stats)
+	}
+
+	translator.SetOptions(Options{FailOnOrphans: true})
+	if _, err := translator.Entries(); err != nil {
+		t.Fatalf("Failed to load entries: %v", err)
+	}
+	err = translator.StrictError()
+	if err == nil || !strings.Contains(err.Error(), "Hermit|XPHB") || strings.Contains(err.Error(), "Sage|XPHB") {
+		t.Errorf("Expected only the Hermit orphan to be fatal, got %v", err)
+	}
+}
+
+func TestScaffoldsAreUntranslated(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_scaffolds")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+	exportDir := filepath.Join(tempDir, "export")
+
+	writeJSONFile(t, filepath.Join(dataDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Acolyte", "source": "XPHB", "entries": []interface{}{"Temple service."}},
+			map[string]interface{}{"name": "Sage", "source": "XPHB"},
+		},
+	})
+	writeJSONFile(t, filepath.Join(dictDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"origin_name": "Acolyte", "origin_source": "XPHB", "name": ""},
+			map[string]interface{}{"origin_name": "Sage", "origin_source": "XPHB", "name": "Мудрець"},
+		},
+	})
+
+	translator := NewTranslator(dataDir, dictDir, exportDir)
+	entries, err := translator.Entries()
+	if err != nil {
+		t.Fatalf("Failed to load entries: %v", err)
+	}
+	if entries[0].Translated != nil || entries[0].Status() != StatusUntranslated {
+		t.Errorf("Expected the scaffold to be untranslated, got %s with %v", entries[0].Status(), entries[0].Translated)
+	}
+	if entries[1].Status() != StatusDraft {
+		t.Errorf("Expected Sage to be a draft, got %s", entries[1].Status())
+	}
+	if stats := translator.Stats(); stats.Translated != 1 || stats.Issues != 0 {
+		t.Errorf("Expected 1 translated entity and no orphans, got %+v with %v", stats, translator.Issues())
+	}
+
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	if stats := translator.Stats(); stats.Translated != 1 || stats.Issues != 0 {
+		t.Errorf("Expected 1 translated entity and no orphans, got %+v with %v", stats, translator.Issues())
+	}
+	data, err := ioutil.ReadFile(filepath.Join(exportDir, "backgrounds.json"))
+	if err != nil {
+		t.Fatalf("Failed to read translated data: %v", err)
+	}
+	if strings.Contains(string(data), "Acolyte") {
+		t.Errorf("Expected the scaffold not to be exported, got %s", data)
+	}
+}
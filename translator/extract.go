@@ -0,0 +1,89 @@
+package translator
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Extract scaffolds the translator's own dictionary with an empty entry for
+// every source entity of the enabled categories that has no translation yet.
+// Entries go to the dictionary file named after their data file; existing
+// entries are left untouched. It returns the number of entries added.
+func (t *Translator) Extract() (int, error) {
+	enabled, err := t.enabledCategories()
+	if err != nil {
+		return 0, err
+	}
+
+	jobs, err := t.planJobs(enabled)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load source data: %w", err)
+	}
+
+	index, err := t.loadDictionaryIndex()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load dictionary data: %w", err)
+	}
+
+	added := 0
+	for _, job := range jobs {
+		sourceData, err := t.loadSourceData(job.file)
+		if err != nil {
+			return added, fmt.Errorf("failed to load source data %s: %w", job.file, err)
+		}
+
+		var missing []interface{}
+		entities, _ := sourceData[job.category.Key].([]interface{})
+		for _, entity := range entities {
+			sourceEntity, ok := entity.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, nameOk := sourceEntity["name"].(string)
+			source, sourceOk := sourceEntity["source"].(string)
+			if !nameOk || !sourceOk {
+				continue
+			}
+			if _, exists := index.lookup(job.category.Key, name+"|"+source); exists {
+				continue
+			}
+
+			dictEntry, err := DictionaryEntry(sourceEntity, nil, make([]string, len(EntryLeaves(sourceEntity))))
+			if err != nil {
+				return added, fmt.Errorf("failed to scaffold %s %s: %w", job.category.Key, name+"|"+source, err)
+			}
+			missing = append(missing, dictEntry)
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		err = appendDictionaryEntries(filepath.Join(t.dictionaryPath, filepath.Base(job.file)), job.category.Key, missing)
+		if err != nil {
+			return added, err
+		}
+		added += len(missing)
+		fmt.Printf("Extracted %d %s entities from %s\n", len(missing), job.category.Key, job.file)
+	}
+
+	return added, nil
+}
+
+// appendDictionaryEntries adds entries to the category array of a dictionary file
+func appendDictionaryEntries(file, category string, entries []interface{}) error {
+	dictData, err := readDictionaryFile(file)
+	if err != nil {
+		return err
+	}
+
+	switch v := dictData[category].(type) {
+	case map[string]interface{}:
+		dictData[category] = append([]interface{}{v}, entries...)
+	case []interface{}:
+		dictData[category] = append(v, entries...)
+	default:
+		dictData[category] = entries
+	}
+
+	return writeDictionaryFile(file, dictData)
+}
@@ -0,0 +1,61 @@
+package translator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_extract")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+
+	writeJSONFile(t, filepath.Join(dataDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Acolyte", "source": "XPHB", "entries": []interface{}{"Temple service."}},
+			map[string]interface{}{"name": "Sage", "source": "XPHB", "entries": []interface{}{"Years of study."}},
+		},
+	})
+	writeJSONFile(t, filepath.Join(dictDir, "backgrounds.json"), map[string]interface{}{
+		"background": map[string]interface{}{"origin_name": "Acolyte", "origin_source": "XPHB", "name": "Аколіт"},
+	})
+
+	if categories := DetectCategories(dataDir); len(categories) != 1 || categories[0] != "background" {
+		t.Errorf("Expected to detect backgrounds only, got %v", categories)
+	}
+
+	translator := NewTranslator(dataDir, dictDir, filepath.Join(tempDir, "export"))
+	added, err := translator.Extract()
+	if err != nil {
+		t.Fatalf("Failed to extract: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("Expected 1 added entry, got %d", added)
+	}
+
+	index, err := translator.loadDictionaryIndex()
+	if err != nil {
+		t.Fatalf("Failed to load dictionary: %v", err)
+	}
+	acolyte, _ := index.lookup("background", "Acolyte|XPHB")
+	if acolyte["name"] != "Аколіт" {
+		t.Errorf("Expected the existing entry to stay, got %v", acolyte)
+	}
+	sage, exists := index.lookup("background", "Sage|XPHB")
+	if !exists || sage["name"] != "" || sage["origin_hash"] == "" {
+		t.Errorf("Expected an empty scaffold for Sage, got %v", sage)
+	}
+
+	// Running again adds nothing
+	added, err = translator.Extract()
+	if err != nil || added != 0 {
+		t.Errorf("Expected nothing to be added again, got %d (%v)", added, err)
+	}
+}
@@ -0,0 +1,253 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SourceLayer is the origin reported for strings no dictionary layer
+// translates; they keep the text of the 5etools source data
+const SourceLayer = "en"
+
+// translatedFields are the dictionary entry fields merged into source entities
+var translatedFields = []string{"name", "entries"}
+
+// StringOrigin records which layer of the fallback chain a translated string came from
+type StringOrigin struct {
+	Category string `json:"category"`
+	Key      string `json:"key"`   // name|source of the entity
+	Path     string `json:"path"`  // location of the string, e.g. entries[1].items[0]
+	Layer    string `json:"layer"` // locale of the dictionary layer or SourceLayer
+}
+
+// dictionaryLayer is one step of the fallback chain
+type dictionaryLayer struct {
+	name  string
+	paths []string
+}
+
+// layers returns the fallback chain starting with the translator's own dictionaries
+func (t *Translator) layers() []dictionaryLayer {
+	name := t.locale
+	if name == "" {
+		name = filepath.Base(t.dictionaryPath)
+	}
+	primary := dictionaryLayer{name: name, paths: append([]string{t.dictionaryPath}, t.extraDictionaries...)}
+	return append([]dictionaryLayer{primary}, t.fallbacks...)
+}
+
+// composeLayers combines the indexes of a fallback chain. An entity takes every
+// translated string from the first layer providing it, so a partial entry in an
+// earlier layer is completed by later ones. Issues and orphan checks only cover
+// the first layer; the fallback layers are checked by their own builds.
+func composeLayers(indexes []*dictionaryIndex, names []string) *dictionaryIndex {
+	composed := newDictionaryIndex()
+	composed.issues = indexes[0].issues
+	for category, keys := range indexes[0].keys {
+		composed.keys[category] = keys
+	}
+
+	for _, category := range categories {
+		var keys []string
+		seen := make(map[string]bool)
+		for _, index := range indexes {
+			for _, key := range index.keys[category.Key] {
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+		}
+
+		for _, key := range keys {
+			composed.compose(indexes, names, category.Key, key)
+		}
+	}
+
+	return composed
+}
+
+// compose combines the layer entries of one entity, replacing its previous combination
+func (d *dictionaryIndex) compose(indexes []*dictionaryIndex, names []string, category, key string) {
+	var entries []map[string]interface{}
+	var entryNames []string
+	for i, index := range indexes {
+		if entry, exists := index.lookup(category, key); exists {
+			entries = append(entries, entry)
+			entryNames = append(entryNames, names[i])
+		}
+	}
+	if len(entries) > 0 {
+		d.setComposed(category, key, entries, entryNames)
+	}
+}
+
+// setComposed stores the entry combined from the layer entries of one entity
+func (d *dictionaryIndex) setComposed(category, key string, entries []map[string]interface{}, names []string) {
+	// Metadata such as origin_hash comes from the first layer with the entity
+	entry := make(map[string]interface{}, len(entries[0]))
+	for field, value := range entries[0] {
+		entry[field] = value
+	}
+
+	var origins []StringOrigin
+	record := func(path, layer string) {
+		origins = append(origins, StringOrigin{Category: category, Key: key, Path: path, Layer: layer})
+	}
+
+	for _, field := range translatedFields {
+		values := make([]interface{}, len(entries))
+		for i, layerEntry := range entries {
+			values[i] = layerEntry[field]
+		}
+		value := layerValue(values, names, field, record)
+		if value == nil {
+			delete(entry, field)
+			continue
+		}
+		entry[field] = value
+	}
+
+	if d.entries[category] == nil {
+		d.entries[category] = make(map[string]map[string]interface{})
+		d.origins[category] = make(map[string][]StringOrigin)
+	}
+	d.entries[category][key] = entry
+	d.origins[category][key] = origins
+}
+
+// layerValue combines the values one field has in each layer. The first layer
+// with a value decides its shape; strings it leaves empty are looked up at the
+// same path in the following layers. Strings no layer translates stay empty
+// and are recorded as SourceLayer, as mergeEntity fills them from the source.
+func layerValue(values []interface{}, names []string, path string, record func(path, layer string)) interface{} {
+	empty := false
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			if v == "" {
+				empty = true
+				continue
+			}
+			record(path, names[i])
+			return v
+		case []interface{}:
+			items := make([]interface{}, len(v))
+			for j := range v {
+				items[j] = layerValue(childValues(values[i:], j), names[i:], fmt.Sprintf("%s[%d]", path, j), record)
+			}
+			return items
+		case map[string]interface{}:
+			fields := make([]string, 0, len(v))
+			for field := range v {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+
+			object := make(map[string]interface{}, len(v))
+			for _, field := range fields {
+				object[field] = layerValue(childValues(values[i:], field), names[i:], path+"."+field, record)
+			}
+			return object
+		default:
+			return v
+		}
+	}
+
+	record(path, SourceLayer)
+	if empty {
+		return ""
+	}
+	return nil
+}
+
+// childValues returns the element or field of every value that has it, nil for the others
+func childValues(values []interface{}, child interface{}) []interface{} {
+	children := make([]interface{}, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case []interface{}:
+			if index, ok := child.(int); ok && index < len(v) {
+				children[i] = v[index]
+			}
+		case map[string]interface{}:
+			if field, ok := child.(string); ok {
+				children[i] = v[field]
+			}
+		}
+	}
+	return children
+}
+
+// fillFromSource replaces the empty strings of a translated value with the
+// source strings at the same path
+func fillFromSource(value, source interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if text, ok := source.(string); ok && v == "" {
+			return text
+		}
+	case []interface{}:
+		sourceItems, _ := source.([]interface{})
+		for i := range v {
+			var sourceItem interface{}
+			if i < len(sourceItems) {
+				sourceItem = sourceItems[i]
+			}
+			v[i] = fillFromSource(v[i], sourceItem)
+		}
+	case map[string]interface{}:
+		sourceObject, _ := source.(map[string]interface{})
+		for field, item := range v {
+			v[field] = fillFromSource(item, sourceObject[field])
+		}
+	}
+	return value
+}
+
+// LayerCount is the number of translated strings one layer provided
+type LayerCount struct {
+	Layer   string
+	Strings int
+}
+
+// LayerCounts counts the strings of the last run by layer, in fallback chain
+// order and ending with SourceLayer
+func (t *Translator) LayerCounts() []LayerCount {
+	counts := make(map[string]int)
+	for _, origin := range t.origins {
+		counts[origin.Layer]++
+	}
+
+	var layerCounts []LayerCount
+	for _, layer := range t.layers() {
+		layerCounts = append(layerCounts, LayerCount{Layer: layer.name, Strings: counts[layer.name]})
+	}
+	return append(layerCounts, LayerCount{Layer: SourceLayer, Strings: counts[SourceLayer]})
+}
+
+// WriteOriginReport writes the origins of the strings translated by the last run as JSON
+func (t *Translator) WriteOriginReport(path string) error {
+	data, err := json.MarshalIndent(t.origins, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal origin report: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write origin report: %w", err)
+	}
+
+	return nil
+}
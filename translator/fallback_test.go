@@ -0,0 +1,127 @@
+package translator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTranslateFallbackChain(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_fallback")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	homebrewDir := filepath.Join(tempDir, "dictionary", "uk-x-homebrew")
+	ukDir := filepath.Join(tempDir, "dictionary", "uk")
+	exportDir := filepath.Join(tempDir, "export")
+
+	writeJSONFile(t, filepath.Join(dataDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Acolyte", "source": "XPHB", "entries": []interface{}{"Temple service.", "Shelter of the faithful."}},
+			map[string]interface{}{"name": "Sage", "source": "XPHB", "entries": []interface{}{"Years of study."}},
+			map[string]interface{}{"name": "Soldier", "source": "XPHB", "entries": []interface{}{"Trained for war."}},
+		},
+	})
+	writeJSONFile(t, filepath.Join(homebrewDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"origin_name": "Acolyte", "origin_source": "XPHB", "name": "Послушник", "entries": []interface{}{"Служба в храмі.", ""}},
+		},
+	})
+	writeJSONFile(t, filepath.Join(ukDir, "backgrounds.json"), map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"origin_name": "Acolyte", "origin_source": "XPHB", "name": "Аколіт", "entries": []interface{}{"Служіння у храмі.", "Притулок вірян."}},
+			map[string]interface{}{"origin_name": "Sage", "origin_source": "XPHB", "name": "Мудрець"},
+		},
+	})
+
+	translator := NewTranslator(dataDir, homebrewDir, exportDir)
+	translator.SetLocale("uk-x-homebrew")
+	translator.AddFallback("uk", ukDir)
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(exportDir, "backgrounds.json"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	var output map[string][]map[string]interface{}
+	if err := json.Unmarshal(data, &output); err != nil {
+		t.Fatalf("Failed to unmarshal output: %v", err)
+	}
+
+	backgrounds := output["background"]
+	if len(backgrounds) != 2 {
+		t.Fatalf("Expected 2 translated backgrounds, got %d", len(backgrounds))
+	}
+
+	acolyte := backgrounds[0]
+	if acolyte["name"] != "Послушник" {
+		t.Errorf("Expected the homebrew name, got %v", acolyte["name"])
+	}
+	entries := acolyte["entries"].([]interface{})
+	if entries[0] != "Служба в храмі." || entries[1] != "Притулок вірян." {
+		t.Errorf("Expected the empty homebrew string to fall back to uk, got %v", entries)
+	}
+
+	sage := backgrounds[1]
+	if sage["name"] != "Мудрець" || sage["entries"].([]interface{})[0] != "Years of study." {
+		t.Errorf("Expected the uk name and the source entries, got %v", sage)
+	}
+
+	expected := []StringOrigin{
+		{Category: "background", Key: "Acolyte|XPHB", Path: "name", Layer: "uk-x-homebrew"},
+		{Category: "background", Key: "Acolyte|XPHB", Path: "entries[0]", Layer: "uk-x-homebrew"},
+		{Category: "background", Key: "Acolyte|XPHB", Path: "entries[1]", Layer: "uk"},
+		{Category: "background", Key: "Sage|XPHB", Path: "name", Layer: "uk"},
+		{Category: "background", Key: "Sage|XPHB", Path: "entries", Layer: SourceLayer},
+	}
+	origins := translator.Origins()
+	if len(origins) != len(expected) {
+		t.Fatalf("Expected %d origins, got %v", len(expected), origins)
+	}
+	for i := range expected {
+		if origins[i] != expected[i] {
+			t.Errorf("Expected origin %v, got %v", expected[i], origins[i])
+		}
+	}
+
+	counts := translator.LayerCounts()
+	if len(counts) != 3 || counts[0] != (LayerCount{"uk-x-homebrew", 2}) || counts[1] != (LayerCount{"uk", 2}) || counts[2] != (LayerCount{SourceLayer, 1}) {
+		t.Errorf("Unexpected layer counts %v", counts)
+	}
+
+	// Fallback entries that match a source are not orphans of the first layer
+	for _, issue := range translator.Issues() {
+		if issue.Kind == IssueOrphan {
+			t.Errorf("Unexpected orphan issue %v", issue)
+		}
+	}
+}
+
+func TestMergeEntityFillsEmptyStringsFromSource(t *testing.T) {
+	translator := NewTranslator("", "", "")
+	source := map[string]interface{}{"name": "Sage", "entries": []interface{}{"Years of study.", map[string]interface{}{"type": "list", "items": []interface{}{"Books"}}}}
+	dictEntry := map[string]interface{}{"name": "", "entries": []interface{}{"Роки навчання.", map[string]interface{}{"type": "list", "items": []interface{}{""}}}}
+
+	translated, err := translator.mergeEntity("background|Sage|XPHB", source, dictEntry)
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+
+	if translated["name"] != "Sage" {
+		t.Errorf("Expected the source name, got %v", translated["name"])
+	}
+	list := translated["entries"].([]interface{})[1].(map[string]interface{})
+	if list["items"].([]interface{})[0] != "Books" {
+		t.Errorf("Expected the source list item, got %v", list["items"])
+	}
+	if dictEntry["entries"].([]interface{})[1].(map[string]interface{})["items"].([]interface{})[0] != "" {
+		t.Errorf("Expected the dictionary entry to stay unchanged")
+	}
+}
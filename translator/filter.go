@@ -0,0 +1,157 @@
+package translator
+
+import (
+	"strings"
+)
+
+// EntryStatus is the translation state of an entity
+type EntryStatus string
+
+const (
+	StatusUntranslated EntryStatus = "untranslated"
+	StatusInherited    EntryStatus = "inherited"
+	StatusDraft        EntryStatus = "draft"
+	StatusReviewed     EntryStatus = "reviewed"
+	StatusStale        EntryStatus = "stale"
+)
+
+// Statuses lists every entry status in workflow order
+var Statuses = []EntryStatus{StatusUntranslated, StatusInherited, StatusDraft, StatusReviewed, StatusStale}
+
+// ReviewedField is the dictionary field that marks a translation as reviewed
+// when set to "reviewed"
+const ReviewedField = "status"
+
+// Status returns the translation state of an entry. Entries are untranslated
+// until the locale's own dictionary entry translates a string, or inherited
+// when only a fallback layer does. Translations are drafts until their
+// dictionary entry is marked reviewed, and stale when the source changed
+// since, whether reviewed or not.
+func (e Entry) Status() EntryStatus {
+	if e.Primary == nil || !hasTranslation(e.Primary) {
+		if e.Dictionary != nil && hasTranslation(e.Dictionary) {
+			return StatusInherited
+		}
+		return StatusUntranslated
+	}
+	for _, issue := range e.Issues {
+		if issue.Kind == IssueStale {
+			return StatusStale
+		}
+	}
+	if status, _ := e.Primary[ReviewedField].(string); status == string(StatusReviewed) {
+		return StatusReviewed
+	}
+	return StatusDraft
+}
+
+// Translated reports whether the locale's own dictionary translates the entry
+func (s EntryStatus) Translated() bool {
+	return s != StatusUntranslated && s != StatusInherited
+}
+
+// EntryFilter selects entries by text and attributes; empty fields match everything
+type EntryFilter struct {
+	Query        string // words that must all appear in a name or the entry text
+	Category     string
+	Source       string // source book abbreviation, such as XPHB
+	Status       EntryStatus
+	WarningsOnly bool // only entries with QA issues
+}
+
+// EntrySearch filters a list of entries, reusing the previous result when
+// a filter only narrows the previous one, for example while a query is typed
+type EntrySearch struct {
+	entries []Entry
+	texts   []string // lowercase searchable text of every entry
+
+	last    *EntryFilter
+	matches []int
+}
+
+// NewEntrySearch indexes entries for searching
+func NewEntrySearch(entries []Entry) *EntrySearch {
+	s := &EntrySearch{entries: entries, texts: make([]string, len(entries))}
+	for i, entry := range entries {
+		s.texts[i] = searchText(entry)
+	}
+	return s
+}
+
+// Update replaces the entry at index, for example after it was saved
+func (s *EntrySearch) Update(index int, entry Entry) {
+	s.entries[index] = entry
+	s.texts[index] = searchText(entry)
+	s.last = nil
+}
+
+// Search returns the indexes of the entries matching filter in list order
+func (s *EntrySearch) Search(filter EntryFilter) []int {
+	filter.Query = strings.ToLower(strings.TrimSpace(filter.Query))
+
+	candidates := s.matches
+	if s.last == nil || !s.narrows(filter) {
+		candidates = make([]int, len(s.entries))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+
+	words := strings.Fields(filter.Query)
+	matches := make([]int, 0, len(candidates))
+	for _, i := range candidates {
+		if s.match(i, filter, words) {
+			matches = append(matches, i)
+		}
+	}
+
+	s.last = &filter
+	s.matches = matches
+	return matches
+}
+
+// narrows reports whether every entry matching filter also matched the last filter
+func (s *EntrySearch) narrows(filter EntryFilter) bool {
+	last := *s.last
+	return strings.HasPrefix(filter.Query, last.Query) &&
+		(last.Category == "" || last.Category == filter.Category) &&
+		(last.Source == "" || last.Source == filter.Source) &&
+		(last.Status == "" || last.Status == filter.Status) &&
+		(!last.WarningsOnly || filter.WarningsOnly)
+}
+
+// match checks a single entry against a filter
+func (s *EntrySearch) match(i int, filter EntryFilter, words []string) bool {
+	entry := s.entries[i]
+	if filter.Category != "" && entry.Category != filter.Category {
+		return false
+	}
+	if filter.Source != "" {
+		if source, _ := entry.Source["source"].(string); !strings.EqualFold(source, filter.Source) {
+			return false
+		}
+	}
+	if filter.Status != "" && entry.Status() != filter.Status {
+		return false
+	}
+	if filter.WarningsOnly && len(entry.Issues) == 0 {
+		return false
+	}
+	for _, word := range words {
+		if !strings.Contains(s.texts[i], word) {
+			return false
+		}
+	}
+	return true
+}
+
+// searchText joins the names and entry text of the source and the translation
+func searchText(entry Entry) string {
+	var parts []string
+	for _, entity := range []map[string]interface{}{entry.Source, entry.Translated} {
+		for _, leaf := range StringLeaves(map[string]interface{}{"name": entity["name"], "entries": entity["entries"]}) {
+			parts = append(parts, leaf.Text)
+		}
+	}
+	return strings.ToLower(strings.Join(parts, "\n"))
+}
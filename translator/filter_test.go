@@ -0,0 +1,102 @@
+package translator
+
+import (
+	"testing"
+)
+
+func TestEntryStatus(t *testing.T) {
+	source := map[string]interface{}{"name": "Light", "source": "XPHB"}
+
+	tests := []struct {
+		entry    Entry
+		expected EntryStatus
+	}{
+		{Entry{Source: source}, StatusUntranslated},
+		{Entry{Source: source, Primary: map[string]interface{}{"name": ""}, Dictionary: map[string]interface{}{"name": ""}}, StatusUntranslated},
+		{Entry{Source: source, Dictionary: map[string]interface{}{"name": "Світло", ReviewedField: "reviewed"}}, StatusInherited},
+		{Entry{Source: source, Primary: map[string]interface{}{"name": ""}, Dictionary: map[string]interface{}{"name": "Світло"}}, StatusInherited},
+		{Entry{Source: source, Primary: map[string]interface{}{"name": "Світло"}, Dictionary: map[string]interface{}{"name": "Світло"}}, StatusDraft},
+		{Entry{Source: source, Primary: map[string]interface{}{"name": "Світло", ReviewedField: "reviewed"}, Dictionary: map[string]interface{}{"name": "Світло"}}, StatusReviewed},
+		{Entry{Source: source, Primary: map[string]interface{}{"name": "Світло", ReviewedField: "reviewed"}, Dictionary: map[string]interface{}{"name": "Світло"}, Issues: []Issue{{Kind: IssueStale}}}, StatusStale},
+	}
+
+	for i, test := range tests {
+		status := test.entry.Status()
+		if status != test.expected {
+			t.Errorf("Expected status %s for entry %d, got %s", test.expected, i, status)
+		}
+		if translated := status == StatusDraft || status == StatusReviewed || status == StatusStale; status.Translated() != translated {
+			t.Errorf("Expected status %s to count as translated: %v", status, translated)
+		}
+	}
+}
+
+func TestEntrySearch(t *testing.T) {
+	entries := []Entry{
+		{
+			Category:   "spell",
+			Key:        "Light|XPHB",
+			Source:     map[string]interface{}{"name": "Light", "source": "XPHB", "entries": []interface{}{"You touch one object."}},
+			Primary:    map[string]interface{}{"name": "Світло", ReviewedField: "reviewed"},
+			Dictionary: map[string]interface{}{"name": "Світло", ReviewedField: "reviewed"},
+			Translated: map[string]interface{}{"name": "Світло", "source": "XPHB", "entries": []interface{}{"Ви торкаєтесь предмета."}},
+		},
+		{
+			Category: "spell",
+			Key:      "Fire Bolt|PHB",
+			Source:   map[string]interface{}{"name": "Fire Bolt", "source": "PHB", "entries": []interface{}{"You hurl a mote of fire."}},
+		},
+		{
+			Category:   "feat",
+			Key:        "Alert|XPHB",
+			Source:     map[string]interface{}{"name": "Alert", "source": "XPHB"},
+			Primary:    map[string]interface{}{"name": "Пильність"},
+			Dictionary: map[string]interface{}{"name": "Пильність"},
+			Translated: map[string]interface{}{"name": "Пильність", "source": "XPHB"},
+			Issues:     []Issue{{Kind: IssueTagMismatch}},
+		},
+	}
+
+	search := NewEntrySearch(entries)
+	tests := []struct {
+		filter   EntryFilter
+		expected []int
+	}{
+		{EntryFilter{}, []int{0, 1, 2}},
+		{EntryFilter{Query: "fire"}, []int{1}},
+		{EntryFilter{Query: "ПРЕДМЕТ"}, []int{0}},
+		{EntryFilter{Query: "you touch"}, []int{0}},
+		{EntryFilter{Query: "you  fire"}, []int{1}},
+		{EntryFilter{Category: "spell"}, []int{0, 1}},
+		{EntryFilter{Source: "xphb"}, []int{0, 2}},
+		{EntryFilter{Status: StatusUntranslated}, []int{1}},
+		{EntryFilter{Status: StatusDraft}, []int{2}},
+		{EntryFilter{WarningsOnly: true}, []int{2}},
+		{EntryFilter{Query: "a"}, []int{1, 2}},
+		{EntryFilter{Query: "al"}, []int{2}},
+		{EntryFilter{Query: "a", Category: "spell"}, []int{1}},
+	}
+
+	for _, test := range tests {
+		matches := search.Search(test.filter)
+		if len(matches) != len(test.expected) {
+			t.Errorf("Expected %v for %+v, got %v", test.expected, test.filter, matches)
+			continue
+		}
+		for i := range matches {
+			if matches[i] != test.expected[i] {
+				t.Errorf("Expected %v for %+v, got %v", test.expected, test.filter, matches)
+				break
+			}
+		}
+	}
+
+	// Updated entries are found by their new text even while narrowing
+	search.Search(EntryFilter{Query: "во"})
+	entries[1].Translated = map[string]interface{}{"name": "Вогняний снаряд", "source": "PHB"}
+	entries[1].Dictionary = map[string]interface{}{"name": "Вогняний снаряд"}
+	search.Update(1, entries[1])
+	if matches := search.Search(EntryFilter{Query: "вог"}); len(matches) != 1 || matches[0] != 1 {
+		t.Errorf("Expected the updated entry to match, got %v", matches)
+	}
+}
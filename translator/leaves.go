@@ -0,0 +1,181 @@
+package translator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StringLeaf is a translatable string of an entry tree and its location
+type StringLeaf struct {
+	Path []interface{} // field names and array indexes leading to the string
+	Text string
+}
+
+// PathString formats the leaf location like origin reports, e.g. entries[1].items[0]
+func (l StringLeaf) PathString() string {
+	var b strings.Builder
+	for _, step := range l.Path {
+		switch v := step.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", v)
+		case string:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(v)
+		}
+	}
+	return b.String()
+}
+
+// untranslatableFields are entry object fields holding identifiers instead of text
+var untranslatableFields = map[string]bool{"type": true, "source": true, "page": true, "style": true}
+
+// hasTranslation reports whether a dictionary entry translates any string;
+// scaffolds written by Extract with an empty name and no entries do not
+func hasTranslation(dictEntry map[string]interface{}) bool {
+	for _, field := range []string{"name", "entries"} {
+		for _, leaf := range StringLeaves(dictEntry[field]) {
+			if strings.TrimSpace(leaf.Text) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StringLeaves returns the translatable strings of a decoded JSON value in
+// document order, with object fields sorted by name
+func StringLeaves(value interface{}, path ...interface{}) []StringLeaf {
+	switch v := value.(type) {
+	case string:
+		return []StringLeaf{{Path: append([]interface{}(nil), path...), Text: v}}
+	case []interface{}:
+		var leaves []StringLeaf
+		for i, item := range v {
+			leaves = append(leaves, StringLeaves(item, append(path, i)...)...)
+		}
+		return leaves
+	case map[string]interface{}:
+		fields := make([]string, 0, len(v))
+		for field := range v {
+			if !untranslatableFields[field] {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+
+		var leaves []StringLeaf
+		for _, field := range fields {
+			leaves = append(leaves, StringLeaves(v[field], append(path, field)...)...)
+		}
+		return leaves
+	}
+	return nil
+}
+
+// LookupString returns the string found at path in a decoded JSON value
+func LookupString(value interface{}, path []interface{}) (string, bool) {
+	for _, step := range path {
+		switch v := value.(type) {
+		case []interface{}:
+			index, ok := step.(int)
+			if !ok || index < 0 || index >= len(v) {
+				return "", false
+			}
+			value = v[index]
+		case map[string]interface{}:
+			field, ok := step.(string)
+			if !ok {
+				return "", false
+			}
+			value = v[field]
+		default:
+			return "", false
+		}
+	}
+	text, ok := value.(string)
+	return text, ok
+}
+
+// SetString replaces the string found at path in a decoded JSON value
+func SetString(value interface{}, path []interface{}, text string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	for i, step := range path {
+		last := i == len(path)-1
+		switch v := value.(type) {
+		case []interface{}:
+			index, ok := step.(int)
+			if !ok || index < 0 || index >= len(v) {
+				return false
+			}
+			if last {
+				v[index] = text
+				return true
+			}
+			value = v[index]
+		case map[string]interface{}:
+			field, ok := step.(string)
+			if !ok {
+				return false
+			}
+			if last {
+				v[field] = text
+				return true
+			}
+			value = v[field]
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// EntryLeaves returns the translatable strings of the name and entries of a source entity
+func EntryLeaves(source map[string]interface{}) []StringLeaf {
+	return StringLeaves(map[string]interface{}{"name": source["name"], "entries": source["entries"]})
+}
+
+// DictionaryEntry builds the dictionary entry of a source entity from the
+// translations of its EntryLeaves, in the same order. The entries keep the
+// shape of the source; untranslated strings stay empty so the source text is
+// used for them. Other fields of the existing entry are preserved.
+func DictionaryEntry(source, existing map[string]interface{}, translations []string) (map[string]interface{}, error) {
+	leaves := EntryLeaves(source)
+	if len(translations) != len(leaves) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(leaves), len(translations))
+	}
+
+	clone, err := cloneValue(map[string]interface{}{"name": source["name"], "entries": source["entries"]})
+	if err != nil {
+		return nil, err
+	}
+	tree := clone.(map[string]interface{})
+
+	translatedEntries := false
+	for i, leaf := range leaves {
+		SetString(tree, leaf.Path, translations[i])
+		if leaf.Path[0] == "entries" && translations[i] != "" {
+			translatedEntries = true
+		}
+	}
+
+	entry := make(map[string]interface{}, len(existing)+5)
+	for field, value := range existing {
+		entry[field] = value
+	}
+	entry["origin_name"] = source["name"]
+	entry["origin_source"] = source["source"]
+	entry["origin_hash"] = EntityHash(source)
+	entry["name"] = tree["name"]
+	if translatedEntries {
+		entry["entries"] = tree["entries"]
+	} else {
+		delete(entry, "entries")
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,40 @@
+package translator
+
+import (
+	"testing"
+)
+
+func TestStringLeaves(t *testing.T) {
+	entity := map[string]interface{}{
+		"name":   "Acolyte",
+		"source": "XPHB",
+		"page":   178.0,
+		"entries": []interface{}{
+			"Temple service.",
+			map[string]interface{}{
+				"type": "list",
+				"items": []interface{}{
+					map[string]interface{}{"type": "item", "name": "Feat:", "entry": "Magic Initiate"},
+				},
+			},
+		},
+	}
+
+	leaves := StringLeaves(entity)
+	expected := []string{"entries[0]", "entries[1].items[0].entry", "entries[1].items[0].name", "name"}
+	if len(leaves) != len(expected) {
+		t.Fatalf("Expected %d leaves, got %v", len(expected), leaves)
+	}
+	for i, path := range expected {
+		if leaves[i].PathString() != path {
+			t.Errorf("Expected leaf %d at %s, got %s", i, path, leaves[i].PathString())
+		}
+		if text, ok := LookupString(entity, leaves[i].Path); !ok || text != leaves[i].Text {
+			t.Errorf("Expected to look up %q at %s, got %q", leaves[i].Text, path, text)
+		}
+	}
+
+	if _, ok := LookupString(entity, []interface{}{"entries", 5}); ok {
+		t.Errorf("Expected a missing path not to be found")
+	}
+}
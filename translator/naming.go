@@ -0,0 +1,119 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultNamingPattern is the pattern of new projects: the translated name
+// followed by the original, as dictionaries used to write names by hand
+const DefaultNamingPattern = "{translated} [{original}]"
+
+// namePattern is a naming pattern split around {translated}
+type namePattern struct {
+	prefix, suffix string
+}
+
+// parseNamePattern splits a naming pattern around its {translated} placeholder
+func parseNamePattern(pattern string) namePattern {
+	i := strings.Index(pattern, "{translated}")
+	if i < 0 {
+		return namePattern{prefix: pattern}
+	}
+	return namePattern{prefix: pattern[:i], suffix: pattern[i+len("{translated}"):]}
+}
+
+// legacyNamingPatterns are bilingual formats dictionaries used to write by
+// hand; names already in one of them are reduced to the bare translation
+var legacyNamingPatterns = []namePattern{
+	parseNamePattern("{translated} [{original}]"),
+	parseNamePattern("{translated} ({original})"),
+}
+
+// ValidateNamingPattern checks that a naming pattern contains the translated name
+func ValidateNamingPattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	if !strings.Contains(pattern, "{translated}") {
+		return fmt.Errorf("naming pattern %q does not contain {translated}", pattern)
+	}
+	return nil
+}
+
+// FormatName formats a translated entity name with a naming pattern such as
+// "{translated} [{original}]". Names that already follow the pattern or a
+// legacy bilingual format are reformatted from their bare translation, so
+// the pattern decides the format whatever the dictionary stores and
+// formatting is idempotent. An empty pattern keeps names as the dictionary
+// writes them.
+func FormatName(pattern, translated, original string) string {
+	if pattern == "" || translated == "" || original == "" {
+		return translated
+	}
+
+	translated = bareName(namePatterns(pattern), translated, original)
+	if !strings.Contains(pattern, "{original}") {
+		return strings.Replace(pattern, "{translated}", translated, 1)
+	}
+	if translated == original {
+		// Untranslated names are not repeated
+		return original
+	}
+
+	return strings.NewReplacer("{translated}", translated, "{original}", original).Replace(pattern)
+}
+
+// namePatterns returns a naming pattern followed by the legacy bilingual formats
+func namePatterns(pattern string) []namePattern {
+	return append([]namePattern{parseNamePattern(pattern)}, legacyNamingPatterns...)
+}
+
+// bareName strips the original name added by any of the patterns from name
+func bareName(patterns []namePattern, name, original string) string {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern.prefix+pattern.suffix, "{original}") {
+			continue
+		}
+		prefix := strings.ReplaceAll(pattern.prefix, "{original}", original)
+		suffix := strings.ReplaceAll(pattern.suffix, "{original}", original)
+		if len(name) > len(prefix)+len(suffix) && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix) {
+			return name[len(prefix) : len(name)-len(suffix)]
+		}
+	}
+	return name
+}
+
+// entityAliases returns the alias list of a translated entity: its original
+// name, the aliases it already had and the alternative names the dictionary
+// provides, without duplicates or the translated name itself. Keeping the
+// exact original name lets name|source references still find the entity.
+func entityAliases(translatedEntity, sourceEntity, dictEntry map[string]interface{}) []interface{} {
+	name, _ := translatedEntity["name"].(string)
+	seen := map[string]bool{strings.ToLower(name): true}
+
+	var aliases []interface{}
+	var add func(value interface{})
+	add = func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			alias := strings.TrimSpace(v)
+			if alias != "" && !seen[strings.ToLower(alias)] {
+				seen[strings.ToLower(alias)] = true
+				aliases = append(aliases, alias)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if alias, ok := item.(string); ok {
+					add(alias)
+				}
+			}
+		}
+	}
+
+	add(sourceEntity["name"])
+	add(sourceEntity["alias"])
+	add(dictEntry["alias"])
+
+	return aliases
+}
@@ -0,0 +1,88 @@
+package translator
+
+import (
+	"testing"
+)
+
+func TestFormatName(t *testing.T) {
+	tests := []struct {
+		pattern, translated, original, expected string
+	}{
+		{"{translated}", "Аколіт", "Acolyte", "Аколіт"},
+		{"{translated}", "Аколіт [Acolyte]", "Acolyte", "Аколіт"},
+		{"{translated}", "Аколіт (Acolyte)", "Acolyte", "Аколіт"},
+		{"", "Аколіт", "Acolyte", "Аколіт"},
+		{"", "Аколіт (Acolyte)", "Acolyte", "Аколіт (Acolyte)"},
+		{"{translated} [{original}]", "Аколіт", "Acolyte", "Аколіт [Acolyte]"},
+		{"{translated} [{original}]", "Аколіт [Acolyte]", "Acolyte", "Аколіт [Acolyte]"},
+		{"{translated} ({original})", "Аколіт [Acolyte]", "Acolyte", "Аколіт (Acolyte)"},
+		{"{original}: {translated}", "Acolyte: Аколіт", "Acolyte", "Acolyte: Аколіт"},
+		{"{translated} [{original}]", "Sage", "Sage", "Sage"},
+		{"{translated} [{original}]", "Шукач [Pilgrim] [Sage]", "Sage", "Шукач [Pilgrim] [Sage]"},
+	}
+
+	for _, test := range tests {
+		name := FormatName(test.pattern, test.translated, test.original)
+		if name != test.expected {
+			t.Errorf("Expected %q for %q with %q, got %q", test.expected, test.translated, test.pattern, name)
+		}
+		if again := FormatName(test.pattern, name, test.original); again != name {
+			t.Errorf("Expected formatting %q again to keep it, got %q", name, again)
+		}
+	}
+
+	if err := ValidateNamingPattern("[{original}]"); err == nil {
+		t.Errorf("Expected a pattern without {translated} to be rejected")
+	}
+}
+
+func TestMergeEntityAppliesNamingPattern(t *testing.T) {
+	translator := NewTranslator("", "", "")
+	translator.SetOptions(Options{NamingPattern: "{translated} [{original}]"})
+
+	translated, err := translator.mergeEntity("background|Acolyte|XPHB", map[string]interface{}{"name": "Acolyte", "source": "XPHB"}, map[string]interface{}{"name": "Аколіт"})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if translated["name"] != "Аколіт [Acolyte]" {
+		t.Errorf("Expected 'Аколіт [Acolyte]', got %v", translated["name"])
+	}
+}
+
+func TestMergeEntityGeneratesAliases(t *testing.T) {
+	translator := NewTranslator("", "", "")
+	translator.SetOptions(Options{GenerateAliases: true, NamingPattern: "{translated} [{original}]"})
+
+	source := map[string]interface{}{"name": "Acolyte", "source": "XPHB", "alias": []interface{}{"Temple Acolyte", "acolyte"}}
+	dictEntry := map[string]interface{}{"name": "Аколіт", "alias": []interface{}{"Послушник", "Temple acolyte", "Аколіт [Acolyte]"}}
+
+	translated, err := translator.mergeEntity("background|Acolyte|XPHB", source, dictEntry)
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+
+	aliases, _ := translated["alias"].([]interface{})
+	expected := []interface{}{"Acolyte", "Temple Acolyte", "Послушник"}
+	if len(aliases) != len(expected) {
+		t.Fatalf("Expected aliases %v, got %v", expected, aliases)
+	}
+	for i := range expected {
+		if aliases[i] != expected[i] {
+			t.Errorf("Expected alias %v, got %v", expected[i], aliases[i])
+		}
+	}
+
+	// The source entity keeps its own aliases
+	if len(source["alias"].([]interface{})) != 2 {
+		t.Errorf("Expected the source aliases to stay unchanged, got %v", source["alias"])
+	}
+
+	// An untranslated name is not its own alias
+	untranslated, err := translator.mergeEntity("background|Sage|XPHB", map[string]interface{}{"name": "Sage", "source": "XPHB"}, map[string]interface{}{"entries": []interface{}{"Роки навчання."}})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if _, exists := untranslated["alias"]; exists {
+		t.Errorf("Expected no alias for an untranslated name, got %v", untranslated["alias"])
+	}
+}
@@ -0,0 +1,61 @@
+package translator
+
+// Options controls optional translator behaviour
+type Options struct {
+	// Issues that make the translation fail
+	FailOnInvalid        bool
+	FailOnOrphans        bool
+	FailOnStale          bool
+	FailOnTagMismatch    bool
+	FailOnCrossReference bool
+
+	// FixCrossReferences replaces the display text of reference tags in
+	// translated entries with the translated name of the linked entity
+	FixCrossReferences bool
+
+	// Force ignores the build cache and rebuilds every output
+	Force bool
+
+	// Jobs is the number of data files processed concurrently; 0 uses all CPUs
+	Jobs int
+
+	// StreamThreshold is the data file size in bytes from which files are
+	// translated one entity at a time instead of being loaded whole; 0 disables streaming
+	StreamThreshold int64
+
+	// NamingPattern formats translated entity names, e.g. "{translated} [{original}]";
+	// empty keeps the dictionary names
+	NamingPattern string
+
+	// GenerateAliases adds the original name and dictionary aliases to the
+	// alias array of translated entities
+	GenerateAliases bool
+}
+
+// StrictOptions returns options that fail on every kind of issue
+func StrictOptions() Options {
+	return Options{
+		FailOnInvalid:        true,
+		FailOnOrphans:        true,
+		FailOnStale:          true,
+		FailOnTagMismatch:    true,
+		FailOnCrossReference: true,
+	}
+}
+
+// failsOn reports whether the given issue kind is fatal with these options
+func (o Options) failsOn(kind IssueKind) bool {
+	switch kind {
+	case IssueInvalid:
+		return o.FailOnInvalid
+	case IssueOrphan:
+		return o.FailOnOrphans
+	case IssueStale:
+		return o.FailOnStale
+	case IssueTagMismatch:
+		return o.FailOnTagMismatch
+	case IssueCrossReference:
+		return o.FailOnCrossReference
+	}
+	return false
+}
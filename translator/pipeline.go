@@ -0,0 +1,196 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// fileJob is a single data file moving through the load, merge and write stages
+type fileJob struct {
+	category  Category
+	file      string // path relative to the data and export directories
+	inputHash string
+	skipped   bool
+	stream    bool // translate straight from the data file instead of loading it
+	source    map[string]interface{}
+	result    *mergeResult
+
+	streamedPath string // temporary output written by a streamed merge
+}
+
+// workers returns the size of the worker pool
+func (t *Translator) workers() int {
+	if t.options.Jobs > 0 {
+		return t.options.Jobs
+	}
+	return runtime.NumCPU()
+}
+
+// runStage runs fn for every job on a bounded pool of workers and returns
+// the errors of all failed jobs joined in job order. Jobs not yet started
+// when ctx is canceled are skipped. progress, if set, is called with the
+// number of finished jobs after each one.
+func runStage(ctx context.Context, jobs []*fileJob, workers int, fn func(job *fileJob) error, progress func(done int)) error {
+	errs := make([]error, len(jobs))
+	indexes := make(chan int)
+
+	var mu sync.Mutex
+	done := 0
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(jobs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := fn(jobs[i]); err != nil {
+					errs[i] = fmt.Errorf("%s: %w", jobs[i].file, err)
+				}
+				if progress != nil {
+					mu.Lock()
+					done++
+					progress(done)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("translation canceled: %w", err)
+	}
+	return errors.Join(errs...)
+}
+
+// planJobs lists the data files of the enabled categories in a stable order
+func (t *Translator) planJobs(enabled []Category) ([]*fileJob, error) {
+	var jobs []*fileJob
+	for _, category := range enabled {
+		files, err := category.dataFiles(t.dataPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			jobs = append(jobs, &fileJob{category: category, file: file})
+		}
+	}
+	return jobs, nil
+}
+
+// loadStage hashes the inputs of a job, reuses its cached output when
+// nothing changed and otherwise reads the source data
+func (t *Translator) loadStage(job *fileJob, dictionaryHash string) error {
+	sourceHash, err := hashFile(filepath.Join(t.dataPath, job.file))
+	if err != nil {
+		return fmt.Errorf("failed to load source data: %w", err)
+	}
+	job.inputHash = hashStrings(Version, t.optionsDigest, job.category.Key, sourceHash, dictionaryHash)
+
+	// Skip outputs whose inputs are unchanged since the last build
+	if cached, ok := t.cache.file(job.file); ok && cached.InputHash == job.inputHash {
+		outputHash, err := hashFile(filepath.Join(t.exportPath, job.file))
+		if err == nil && outputHash == cached.OutputHash {
+			job.skipped = true
+			job.result = &mergeResult{sources: cached.Sources, matched: cached.Matched, translated: cached.Translated, issues: cached.Issues}
+			t.nextCache.setFile(job.file, cached)
+			t.cache.keepEntities(job.category.Key, cached.Translated, t.nextCache)
+			return nil
+		}
+	}
+
+	// Large files are read while merging, one entity at a time
+	if t.shouldStream(job.file) {
+		job.stream = true
+		return nil
+	}
+
+	job.source, err = t.loadSourceData(job.file)
+	if err != nil {
+		return fmt.Errorf("failed to load source data: %w", err)
+	}
+	return nil
+}
+
+// mergeStage applies the dictionary to the source data of a job
+func (t *Translator) mergeStage(job *fileJob, index *dictionaryIndex) error {
+	if job.skipped {
+		return nil
+	}
+
+	if job.stream {
+		result, err := t.streamFile(job, index.entries[job.category.Key])
+		if err != nil {
+			return err
+		}
+		job.result = result
+		return nil
+	}
+
+	result, err := t.applyTranslations(job.category.Key, job.source, index.entries[job.category.Key])
+	if err != nil {
+		return fmt.Errorf("failed to apply translations: %w", err)
+	}
+	job.result = result
+	job.source = nil
+	return nil
+}
+
+// writeStage writes the translated data of a job and records it in the build cache
+func (t *Translator) writeStage(job *fileJob) error {
+	if job.skipped {
+		return nil
+	}
+
+	if job.streamedPath != "" {
+		err := os.Rename(job.streamedPath, filepath.Join(t.exportPath, job.file))
+		if err != nil {
+			return fmt.Errorf("failed to write translated data: %w", err)
+		}
+		job.streamedPath = ""
+	} else {
+		err := t.writeTranslatedData(job.file, job.result.data)
+		if err != nil {
+			return fmt.Errorf("failed to write translated data: %w", err)
+		}
+	}
+
+	outputHash, err := hashFile(filepath.Join(t.exportPath, job.file))
+	if err != nil {
+		return fmt.Errorf("failed to hash translated data: %w", err)
+	}
+
+	t.nextCache.setFile(job.file, fileCacheEntry{
+		InputHash:  job.inputHash,
+		OutputHash: outputHash,
+		Sources:    job.result.sources,
+		Matched:    job.result.matched,
+		Translated: job.result.translated,
+		Issues:     job.result.issues,
+	})
+	job.result.data = nil
+	return nil
+}
+
+// removeStreamed deletes temporary outputs of streamed jobs that were never written
+func removeStreamed(jobs []*fileJob) {
+	for _, job := range jobs {
+		if job.streamedPath != "" {
+			os.Remove(job.streamedPath)
+			job.streamedPath = ""
+		}
+	}
+}
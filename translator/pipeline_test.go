@@ -0,0 +1,225 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTranslateWithWorkersKeepsOrder(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_pipeline")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+	exportDir := filepath.Join(tempDir, "export")
+
+	writeJSONFile(t, filepath.Join(dataDir, "spells", "spells-xphb.json"), map[string]interface{}{
+		"spell": []interface{}{
+			map[string]interface{}{"name": "Light", "source": "XPHB"},
+			map[string]interface{}{"name": "Guidance", "source": "XPHB"},
+		},
+	})
+	writeJSONFile(t, filepath.Join(dataDir, "spells", "spells-phb.json"), map[string]interface{}{
+		"spell": []interface{}{
+			map[string]interface{}{"name": "Light", "source": "PHB"},
+		},
+	})
+	writeJSONFile(t, filepath.Join(dictDir, "spells.json"), map[string]interface{}{
+		"spell": []interface{}{
+			map[string]interface{}{"origin_name": "Guidance", "origin_source": "XPHB", "name": "Настанова"},
+			map[string]interface{}{"origin_name": "Light", "origin_source": "XPHB", "name": "Світло"},
+			map[string]interface{}{"origin_name": "Light", "origin_source": "PHB", "name": "Світло"},
+			map[string]interface{}{"origin_name": "Fireball", "origin_source": "XPHB", "name": "Вогняна куля"},
+		},
+	})
+
+	translator := NewTranslator(dataDir, dictDir, exportDir)
+	translator.SetCategories([]string{"spell"})
+	translator.SetOptions(Options{Jobs: 4})
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(exportDir, "spells", "spells-xphb.json"))
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	var output map[string]interface{}
+	if err := json.Unmarshal(content, &output); err != nil {
+		t.Fatalf("Failed to unmarshal output file: %v", err)
+	}
+
+	// Output keeps the source order
+	spells := output["spell"].([]interface{})
+	if len(spells) != 2 || spells[0].(map[string]interface{})["name"] != "Світло" {
+		t.Errorf("Expected Light then Guidance in source order, got %v", spells)
+	}
+
+	if _, err := os.Stat(filepath.Join(exportDir, "spells", "spells-phb.json")); err != nil {
+		t.Errorf("Expected spells-phb.json to be written: %v", err)
+	}
+
+	// Only entries matched by no file are orphans
+	issues := translator.Issues()
+	if len(issues) != 1 || issues[0].Kind != IssueOrphan || issues[0].Key != "Fireball|XPHB" {
+		t.Errorf("Expected a single Fireball orphan, got %v", issues)
+	}
+}
+
+func TestRunStageOverlapsWorkers(t *testing.T) {
+	const workers = 4
+	jobs := make([]*fileJob, workers)
+	for i := range jobs {
+		jobs[i] = &fileJob{file: fmt.Sprintf("file-%d.json", i)}
+	}
+
+	// Every job waits until all of them run at once, then they finish in
+	// reverse order, each one after the job behind it
+	var mu sync.Mutex
+	running := 0
+	allRunning := make(chan struct{})
+	finished := make([]chan struct{}, workers+1)
+	for i := range finished {
+		finished[i] = make(chan struct{})
+	}
+	close(finished[workers])
+
+	err := runStage(context.Background(), jobs, workers, func(job *fileJob) error {
+		i := indexOfJob(jobs, job)
+		mu.Lock()
+		running++
+		if running == workers {
+			close(allRunning)
+		}
+		mu.Unlock()
+
+		select {
+		case <-allRunning:
+		case <-time.After(5 * time.Second):
+			return errors.New("workers did not overlap")
+		}
+		<-finished[i+1]
+		close(finished[i])
+		return errors.New("failed")
+	}, nil)
+
+	if err == nil || strings.Contains(err.Error(), "did not overlap") {
+		t.Fatalf("Expected every job to run concurrently, got %v", err)
+	}
+	// Errors are joined in job order although the jobs finished in reverse
+	lines := strings.Split(err.Error(), "\n")
+	for i, line := range lines {
+		if line != jobs[i].file+": failed" {
+			t.Errorf("Expected the error of %s at line %d, got %q", jobs[i].file, i, line)
+		}
+	}
+}
+
+// indexOfJob returns the position of job in jobs
+func indexOfJob(jobs []*fileJob, job *fileJob) int {
+	for i := range jobs {
+		if jobs[i] == job {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestTranslateAggregatesWorkerErrors(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_pipeline")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data", "bestiary")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatalf("Failed to create data directory: %v", err)
+	}
+	for _, name := range []string{"bestiary-mm.json", "bestiary-xmm.json"} {
+		if err := ioutil.WriteFile(filepath.Join(dataDir, name), []byte("{broken"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	translator := NewTranslator(filepath.Join(tempDir, "data"), filepath.Join(tempDir, "dictionary"), filepath.Join(tempDir, "export"))
+	translator.SetCategories([]string{"monster"})
+	translator.SetOptions(Options{Jobs: 2})
+
+	err = translator.Translate()
+	if err == nil {
+		t.Fatalf("Expected translation to fail")
+	}
+
+	message := err.Error()
+	if !strings.Contains(message, "bestiary-mm.json") || !strings.Contains(message, "bestiary-xmm.json") {
+		t.Errorf("Expected errors of both files, got %v", err)
+	}
+
+	if strings.Index(message, "bestiary-mm.json") > strings.Index(message, "bestiary-xmm.json") {
+		t.Errorf("Expected errors in file order, got %v", err)
+	}
+}
+
+func TestTranslateReportsProgressAndCancels(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_pipeline")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	exportDir := filepath.Join(tempDir, "export")
+	for _, name := range []string{"spells-phb.json", "spells-xphb.json"} {
+		writeJSONFile(t, filepath.Join(dataDir, "spells", name), map[string]interface{}{
+			"spell": []interface{}{map[string]interface{}{"name": "Light", "source": "PHB"}},
+		})
+	}
+
+	translator := NewTranslator(dataDir, filepath.Join(tempDir, "dictionary"), exportDir)
+	translator.SetCategories([]string{"spell"})
+	translator.SetOptions(Options{Jobs: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = translator.TranslateContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a canceled translation, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(exportDir, "spells", "spells-phb.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected a canceled translation to write nothing")
+	}
+
+	var reports []Progress
+	translator.SetProgress(func(progress Progress) {
+		reports = append(reports, progress)
+	})
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+
+	// Every stage reports its start and both files
+	if len(reports) != 9 {
+		t.Fatalf("Expected 9 progress reports, got %d: %v", len(reports), reports)
+	}
+	last := reports[len(reports)-1]
+	if last.Stage != StageWrite || last.Done != 2 || last.Total != 2 || last.Fraction() != 1 {
+		t.Errorf("Expected a finished write stage last, got %+v", last)
+	}
+	if fraction := reports[3].Fraction(); fraction != float32(1)/3 {
+		t.Errorf("Expected the merge stage to start at a third, got %v", fraction)
+	}
+}
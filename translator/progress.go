@@ -0,0 +1,50 @@
+package translator
+
+// Stages of a translation run, in the order they run
+const (
+	StageLoad  = "load"
+	StageMerge = "merge"
+	StageWrite = "write"
+)
+
+// stages lists the stages reported by Progress in run order
+var stages = []string{StageLoad, StageMerge, StageWrite}
+
+// Progress reports how many data files a stage of a translation run has finished
+type Progress struct {
+	Stage string
+	Done  int
+	Total int
+}
+
+// Fraction returns the share of the whole run that is finished, between 0 and 1
+func (p Progress) Fraction() float32 {
+	for i, stage := range stages {
+		if stage != p.Stage {
+			continue
+		}
+		done := float32(1)
+		if p.Total > 0 {
+			done = float32(p.Done) / float32(p.Total)
+		}
+		return (float32(i) + done) / float32(len(stages))
+	}
+	return 0
+}
+
+// SetProgress sets a function called whenever a data file finishes a stage.
+// Calls are serialized but come from the worker goroutines.
+func (t *Translator) SetProgress(progress func(Progress)) {
+	t.progress = progress
+}
+
+// stageProgress returns the callback runStage reports finished jobs of a stage to
+func (t *Translator) stageProgress(stage string, total int) func(done int) {
+	if t.progress == nil {
+		return nil
+	}
+	t.progress(Progress{Stage: stage, Total: total})
+	return func(done int) {
+		t.progress(Progress{Stage: stage, Done: done, Total: total})
+	}
+}
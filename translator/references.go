@@ -0,0 +1,196 @@
+package translator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// referenceIndex maps the targets of inline reference tags to the canonical
+// translated names of the entities they link to, across all categories
+type referenceIndex struct {
+	names  map[string]string // category|name|source, lowercase
+	byName map[string]string // category|name for names translated under a single source
+}
+
+// newReferenceIndex collects the translated names of every dictionary entry
+func newReferenceIndex(index *dictionaryIndex, pattern string) *referenceIndex {
+	references := &referenceIndex{names: make(map[string]string), byName: make(map[string]string)}
+
+	patterns := namePatterns(pattern)
+	ambiguous := make(map[string]bool)
+	for _, category := range categories {
+		for key, entry := range index.entries[category.Key] {
+			name, _ := entry["name"].(string)
+			originName, _ := entry["origin_name"].(string)
+			if name == "" {
+				continue
+			}
+			// References show the bare translation, not the bilingual entity name
+			name = bareName(patterns, name, originName)
+
+			references.names[category.Key+"|"+strings.ToLower(key)] = name
+
+			nameKey := category.Key + "|" + strings.ToLower(originName)
+			if other, exists := references.byName[nameKey]; exists && other != name {
+				ambiguous[nameKey] = true
+			}
+			references.byName[nameKey] = name
+		}
+	}
+	for nameKey := range ambiguous {
+		delete(references.byName, nameKey)
+	}
+
+	return references
+}
+
+// canonical returns the translated name a reference tag should display
+func (r *referenceIndex) canonical(tag Tag) (string, bool) {
+	if r == nil || len(tag.Parts) == 0 {
+		return "", false
+	}
+	category, ok := tagCategory(tag.Name)
+	if !ok {
+		return "", false
+	}
+
+	name := strings.ToLower(strings.TrimSpace(tag.Parts[0]))
+	if len(tag.Parts) > 1 && strings.TrimSpace(tag.Parts[1]) != "" {
+		translated, exists := r.names[category+"|"+name+"|"+strings.ToLower(strings.TrimSpace(tag.Parts[1]))]
+		return translated, exists
+	}
+	translated, exists := r.byName[category+"|"+name]
+	return translated, exists
+}
+
+// check returns an issue for every reference tag in value whose display
+// text disagrees with the canonical translation of its target
+func (r *referenceIndex) check(key string, value interface{}) []Issue {
+	if r == nil {
+		return nil
+	}
+
+	var mismatches []string
+	walkStrings(value, func(s string) {
+		r.walkTags(s, func(tag Tag, translated string) {
+			if !strings.EqualFold(tag.Display(), translated) {
+				mismatches = append(mismatches, fmt.Sprintf("{@%s %s} shows %q instead of %q", tag.Name, tag.Parts[0], tag.Display(), translated))
+			}
+		})
+	})
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	sort.Strings(mismatches)
+	return []Issue{{Kind: IssueCrossReference, Key: key, Message: strings.Join(mismatches, "; ")}}
+}
+
+// referenceIssues checks the reference tags of a dictionary entry as they are
+// exported, so mismatches FixCrossReferences rewrites are not reported
+func (t *Translator) referenceIssues(key string, dictEntry map[string]interface{}) []Issue {
+	entries := dictEntry["entries"]
+	if t.options.FixCrossReferences && t.references != nil {
+		if clone, err := cloneValue(entries); err == nil {
+			entries = t.references.fix(clone)
+		}
+	}
+	return t.references.check(key, entries)
+}
+
+// walkTags calls fn for every reference tag in text with a canonical translation,
+// including tags nested in formatting tags
+func (r *referenceIndex) walkTags(text string, fn func(tag Tag, translated string)) {
+	for _, tag := range ParseTags(text) {
+		if translated, ok := r.canonical(tag); ok {
+			fn(tag, translated)
+		} else if strings.Contains(strings.Join(tag.Parts, "|"), "{@") {
+			r.walkTags(strings.Join(tag.Parts, "|"), fn)
+		}
+	}
+}
+
+// fix returns value with the display text of every reference tag replaced
+// by the canonical translation of its target
+func (r *referenceIndex) fix(value interface{}) interface{} {
+	if r == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.fixString(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = r.fix(v[i])
+		}
+	case map[string]interface{}:
+		for field, item := range v {
+			v[field] = r.fix(item)
+		}
+	}
+	return value
+}
+
+// fixString rewrites the reference tags of a single string
+func (r *referenceIndex) fixString(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, tag := range ParseTags(text) {
+		b.WriteString(text[last:tag.Start])
+		last = tag.End
+
+		translated, ok := r.canonical(tag)
+		if !ok {
+			body := strings.Join(tag.Parts, "|")
+			if strings.Contains(body, "{@") {
+				b.WriteString("{@" + tag.Name + " " + r.fixString(body) + "}")
+			} else {
+				b.WriteString(text[tag.Start:tag.End])
+			}
+			continue
+		}
+		if strings.EqualFold(tag.Display(), translated) {
+			b.WriteString(text[tag.Start:tag.End])
+			continue
+		}
+
+		parts := append([]string(nil), tag.Parts...)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		parts[2] = translated
+		b.WriteString("{@" + tag.Name + " " + strings.Join(parts, "|") + "}")
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// hash returns a hash of the canonical names value refers to, so cached
+// entities are rebuilt when a referenced translation changes
+func (r *referenceIndex) hash(value interface{}) string {
+	if r == nil {
+		return ""
+	}
+
+	var names []string
+	walkStrings(value, func(s string) {
+		r.walkTags(s, func(tag Tag, translated string) {
+			names = append(names, tag.Name+" "+tag.Target()+" "+translated)
+		})
+	})
+	return hashStrings(names...)
+}
+
+// tagCategory returns the category of the entities a reference tag links to
+func tagCategory(tagName string) (string, bool) {
+	for _, category := range categories {
+		for _, name := range category.Tags {
+			if name == tagName {
+				return category.Key, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,104 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+)
+
+func testReferenceIndex() *referenceIndex {
+	index := newDictionaryIndex()
+	index.add("feat", map[string]interface{}{"origin_name": "Magic Initiate", "origin_source": "XPHB", "name": "Посвячений у магію [Magic Initiate]"})
+	index.add("spell", map[string]interface{}{"origin_name": "Fireball", "origin_source": "XPHB", "name": "Вогняна куля"})
+	index.add("spell", map[string]interface{}{"origin_name": "Fireball", "origin_source": "PHB", "name": "Вогняний шар"})
+	index.add("condition", map[string]interface{}{"origin_name": "Blinded", "origin_source": "XPHB", "name": "Засліплений"})
+	return newReferenceIndex(index, "{translated} [{original}]")
+}
+
+func TestReferenceIndexCheck(t *testing.T) {
+	references := testReferenceIndex()
+
+	entries := []interface{}{
+		"Take {@feat Magic Initiate|XPHB|посвячений у магію} or {@feat Magic Initiate|XPHB}.",
+		map[string]interface{}{"type": "list", "items": []interface{}{"{@b {@spell Fireball|PHB|Вогняна куля}}", "{@spell Fireball}", "{@condition Blinded|XPHB|Засліплений}"}},
+	}
+
+	issues := references.check("Sage|XPHB", entries)
+	if len(issues) != 1 || issues[0].Kind != IssueCrossReference {
+		t.Fatalf("Expected one cross-reference issue, got %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, `{@feat Magic Initiate} shows "Magic Initiate" instead of "Посвячений у магію"`) {
+		t.Errorf("Expected the untranslated feat reference to be reported, got %s", issues[0].Message)
+	}
+	if !strings.Contains(issues[0].Message, `{@spell Fireball} shows "Вогняна куля" instead of "Вогняний шар"`) {
+		t.Errorf("Expected the nested spell reference to be reported, got %s", issues[0].Message)
+	}
+	if strings.Contains(issues[0].Message, "{@spell Fireball} shows \"Fireball\"") {
+		t.Errorf("Expected an ambiguous reference without source to be ignored, got %s", issues[0].Message)
+	}
+	if strings.Contains(issues[0].Message, "посвячений") || strings.Contains(issues[0].Message, "Blinded") {
+		t.Errorf("Expected matching display text to be accepted, got %s", issues[0].Message)
+	}
+}
+
+func TestReferenceIndexFix(t *testing.T) {
+	references := testReferenceIndex()
+
+	fixed := references.fix([]interface{}{
+		"Take {@feat Magic Initiate|XPHB} and {@b {@spell Fireball|PHB|Вогняна куля}}, not {@spell Unknown|XPHB}.",
+		"{@condition blinded||сліпий}",
+	}).([]interface{})
+
+	if fixed[0] != "Take {@feat Magic Initiate|XPHB|Посвячений у магію} and {@b {@spell Fireball|PHB|Вогняний шар}}, not {@spell Unknown|XPHB}." {
+		t.Errorf("Unexpected fixed text: %s", fixed[0])
+	}
+	if fixed[1] != "{@condition blinded||Засліплений}" {
+		t.Errorf("Expected a reference without source to use the unique translation, got %s", fixed[1])
+	}
+}
+
+func TestMergeEntityFixesCrossReferences(t *testing.T) {
+	translator := NewTranslator("", "", "")
+	translator.SetOptions(Options{FixCrossReferences: true})
+	translator.references = testReferenceIndex()
+
+	translated, err := translator.mergeEntity("background|Sage|XPHB",
+		map[string]interface{}{"name": "Sage", "entries": []interface{}{"You gain the {@feat Magic Initiate|XPHB} feat."}},
+		map[string]interface{}{"name": "Мудрець", "entries": []interface{}{"Ви отримуєте рису {@feat Magic Initiate|XPHB}."}})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+
+	if entries := translated["entries"].([]interface{}); entries[0] != "Ви отримуєте рису {@feat Magic Initiate|XPHB|Посвячений у магію}." {
+		t.Errorf("Expected the reference to show the translated feat name, got %v", entries[0])
+	}
+}
+
+func TestApplyTranslationsChecksFixedReferences(t *testing.T) {
+	source := map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{"name": "Sage", "source": "XPHB", "entries": []interface{}{"You gain the {@feat Magic Initiate|XPHB} feat."}},
+		},
+	}
+	dictionary := map[string]map[string]interface{}{
+		"Sage|XPHB": {"name": "Мудрець", "entries": []interface{}{"Ви отримуєте рису {@feat Magic Initiate|XPHB}."}},
+	}
+
+	translator := NewTranslator("", "", "")
+	translator.references = testReferenceIndex()
+	result, err := translator.applyTranslations("background", source, dictionary)
+	if err != nil {
+		t.Fatalf("Failed to apply translations: %v", err)
+	}
+	if len(result.issues) != 1 || result.issues[0].Kind != IssueCrossReference {
+		t.Errorf("Expected the mismatch to be reported without fixing, got %v", result.issues)
+	}
+
+	translator.SetOptions(Options{FixCrossReferences: true, FailOnCrossReference: true})
+	result, err = translator.applyTranslations("background", source, dictionary)
+	if err != nil {
+		t.Fatalf("Failed to apply translations: %v", err)
+	}
+	if len(result.issues) != 0 {
+		t.Errorf("Expected fixed references not to be reported, got %v", result.issues)
+	}
+}
@@ -0,0 +1,31 @@
+package translator
+
+// Stats summarizes a translation run
+type Stats struct {
+	Files          int // data files processed
+	SkippedFiles   int // data files reused from the build cache
+	SourceEntities int // entities in the processed data files
+	Translated     int // entities with a dictionary entry that translates anything
+	Issues         int // issues found
+}
+
+// Coverage returns the share of source entities that are translated, in percent
+func (s Stats) Coverage() float64 {
+	if s.SourceEntities == 0 {
+		return 0
+	}
+	return float64(s.Translated) * 100 / float64(s.SourceEntities)
+}
+
+// collectStats sums up the results of all jobs
+func collectStats(jobs []*fileJob, issues []Issue) Stats {
+	stats := Stats{Files: len(jobs), Issues: len(issues)}
+	for _, job := range jobs {
+		if job.skipped {
+			stats.SkippedFiles++
+		}
+		stats.SourceEntities += job.result.sources
+		stats.Translated += len(job.result.translated)
+	}
+	return stats
+}
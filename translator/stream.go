@@ -0,0 +1,310 @@
+package translator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// streamTranslations translates a data file one top level entity at a time.
+// Entities of the category are decoded, merged and encoded individually and
+// every other top level value is copied through, so memory use stays bounded
+// by the largest single entity instead of the whole file.
+// Unchanged entities are copied from previous, the last export of the file,
+// when it is not nil.
+func (t *Translator) streamTranslations(category string, in io.Reader, out io.Writer, dictionary map[string]map[string]interface{}, previous *previousOutput) (*mergeResult, error) {
+	result := &mergeResult{}
+	dec := json.NewDecoder(bufio.NewReader(in))
+	w := bufio.NewWriter(out)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return result, err
+	}
+	w.WriteString("{")
+
+	found := false
+	translatedCount := 0
+	for first := true; dec.More(); first = false {
+		token, err := dec.Token()
+		if err != nil {
+			return result, fmt.Errorf("failed to read source data: %w", err)
+		}
+		key, ok := token.(string)
+		if !ok {
+			return result, fmt.Errorf("unexpected token %v in source data", token)
+		}
+
+		if !first {
+			w.WriteString(",")
+		}
+		keyJSON, _ := json.Marshal(key)
+		fmt.Fprintf(w, "\n    %s: ", keyJSON)
+
+		if key != category {
+			// Copy every other top level value through unchanged
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return result, fmt.Errorf("failed to read '%s' from source data: %w", key, err)
+			}
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, raw, "    ", "    "); err != nil {
+				return result, err
+			}
+			w.Write(indented.Bytes())
+			continue
+		}
+
+		found = true
+		if err := expectDelim(dec, '['); err != nil {
+			return result, fmt.Errorf("'%s' field is not an array", category)
+		}
+		w.WriteString("[")
+
+		for dec.More() {
+			// The raw entity is kept so the cache can hash it without encoding it again
+			var raw json.RawMessage
+			var sourceEntity map[string]interface{}
+			if err := dec.Decode(&raw); err != nil {
+				return result, fmt.Errorf("failed to read %s entity: %w", category, err)
+			}
+			if err := json.Unmarshal(raw, &sourceEntity); err != nil {
+				return result, fmt.Errorf("failed to read %s entity: %w", category, err)
+			}
+			result.sources++
+
+			name, nameOk := sourceEntity["name"].(string)
+			source, sourceOk := sourceEntity["source"].(string)
+			if !nameOk || !sourceOk {
+				continue
+			}
+
+			key := name + "|" + source
+			dictEntry, exists := dictionary[key]
+			if !exists {
+				continue
+			}
+
+			result.matched = append(result.matched, key)
+			result.issues = append(result.issues, checkEntry(key, sourceEntity, dictEntry)...)
+			result.issues = append(result.issues, t.referenceIssues(key, dictEntry)...)
+			if !hasTranslation(dictEntry) {
+				continue
+			}
+			result.translated = append(result.translated, key)
+
+			entityJSON, err := t.streamEntity(category, key, raw, sourceEntity, dictEntry, previous)
+			if err != nil {
+				return result, fmt.Errorf("failed to merge %s %s: %w", category, key, err)
+			}
+			if translatedCount > 0 {
+				w.WriteString(",")
+			}
+			w.WriteString("\n        ")
+			w.Write(entityJSON)
+			translatedCount++
+		}
+
+		if err := expectDelim(dec, ']'); err != nil {
+			return result, err
+		}
+		if translatedCount > 0 {
+			w.WriteString("\n    ")
+		}
+		w.WriteString("]")
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return result, err
+	}
+	if !found {
+		return result, fmt.Errorf("no '%s' field found in source data", category)
+	}
+	w.WriteString("\n}")
+
+	fmt.Printf("Found %d source %s entities\n", result.sources, category)
+	fmt.Printf("Created %d translated %s entities\n", translatedCount, category)
+
+	return result, w.Flush()
+}
+
+// streamEntity returns the indented output of a streamed entity. The build
+// cache only records its input hash; an unchanged entity is copied from the
+// previous export instead of being merged again.
+func (t *Translator) streamEntity(category, key string, raw json.RawMessage, sourceEntity, dictEntry map[string]interface{}, previous *previousOutput) ([]byte, error) {
+	cacheKey := category + "|" + key
+	var inputHash string
+	if t.nextCache != nil {
+		inputHash = t.entityInputHash(hashStrings(string(raw)), dictEntry)
+		if cached, ok := t.cache.entity(cacheKey); ok && cached.InputHash == inputHash {
+			if output := previous.lookup(key); output != nil {
+				t.nextCache.setEntity(cacheKey, entityCacheEntry{InputHash: inputHash})
+				return output, nil
+			}
+		}
+	}
+
+	translatedEntity, err := t.translateEntity(sourceEntity, dictEntry)
+	if err != nil {
+		return nil, err
+	}
+	if t.nextCache != nil {
+		t.nextCache.setEntity(cacheKey, entityCacheEntry{InputHash: inputHash})
+	}
+	return json.MarshalIndent(translatedEntity, "        ", "    ")
+}
+
+// previousOutput reads the translated entities of the last export of a
+// streamed file in order. The translated keys recorded for the file in the
+// build cache name its entities, so an entity is found by skipping the ones before it.
+type previousOutput struct {
+	file      *os.File
+	dec       *json.Decoder
+	positions map[string][]int // entity key to its positions in the array
+	next      int              // position of the next entity the decoder reads
+}
+
+// openPreviousOutput opens the last export of a streamed job when it is the
+// one the build cache describes, or returns nil
+func (t *Translator) openPreviousOutput(job *fileJob) *previousOutput {
+	if t.cache == nil {
+		return nil
+	}
+	cached, ok := t.cache.file(job.file)
+	if !ok || len(cached.Translated) == 0 {
+		return nil
+	}
+	path := filepath.Join(t.exportPath, job.file)
+	if outputHash, err := hashFile(path); err != nil || outputHash != cached.OutputHash {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	p := &previousOutput{file: file, dec: json.NewDecoder(bufio.NewReader(file)), positions: make(map[string][]int)}
+	for i, key := range cached.Translated {
+		p.positions[key] = append(p.positions[key], i)
+	}
+
+	// Move the decoder to the start of the entity array
+	if expectDelim(p.dec, '{') != nil {
+		p.close()
+		return nil
+	}
+	for p.dec.More() {
+		token, err := p.dec.Token()
+		if err != nil {
+			break
+		}
+		if token == job.category.Key {
+			if expectDelim(p.dec, '[') != nil {
+				break
+			}
+			return p
+		}
+		var skipped json.RawMessage
+		if err := p.dec.Decode(&skipped); err != nil {
+			break
+		}
+	}
+	p.close()
+	return nil
+}
+
+// lookup returns the output of the entity with the given key as the export
+// holds it, indented like a streamed entity, or nil when it is not ahead of
+// the entities already read
+func (p *previousOutput) lookup(key string) []byte {
+	if p == nil || p.dec == nil {
+		return nil
+	}
+	position := -1
+	for _, i := range p.positions[key] {
+		if i >= p.next {
+			position = i
+			break
+		}
+	}
+	if position < 0 {
+		return nil
+	}
+
+	// Skipped entities reuse the buffer of raw
+	var raw json.RawMessage
+	for ; p.next <= position; p.next++ {
+		if err := p.dec.Decode(&raw); err != nil {
+			p.dec = nil
+			return nil
+		}
+	}
+	return raw
+}
+
+// close releases the previous export file
+func (p *previousOutput) close() {
+	if p != nil {
+		p.file.Close()
+	}
+}
+
+// expectDelim reads the next token and checks that it is the given delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read source data: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("expected '%s' in source data, got %v", delim, token)
+	}
+	return nil
+}
+
+// streamFile streams a source data file into a temporary file next to its
+// export location; writeStage moves it into place once the run succeeded
+func (t *Translator) streamFile(job *fileJob, dictionary map[string]map[string]interface{}) (*mergeResult, error) {
+	in, err := os.Open(filepath.Join(t.dataPath, job.file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source data: %w", err)
+	}
+	defer in.Close()
+
+	outputPath := filepath.Join(t.exportPath, job.file)
+	err = os.MkdirAll(filepath.Dir(outputPath), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(outputPath), filepath.Base(outputPath)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary output: %w", err)
+	}
+
+	previous := t.openPreviousOutput(job)
+	defer previous.close()
+
+	result, err := t.streamTranslations(job.category.Key, in, out, dictionary, previous)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return nil, err
+	}
+
+	job.streamedPath = out.Name()
+	return result, nil
+}
+
+// shouldStream reports whether a data file is large enough to be streamed
+func (t *Translator) shouldStream(file string) bool {
+	if t.options.StreamThreshold <= 0 {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(t.dataPath, file))
+	return err == nil && info.Size() >= t.options.StreamThreshold
+}
@@ -0,0 +1,394 @@
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamTranslationsMatchesApplyTranslations(t *testing.T) {
+	sourceData := map[string]interface{}{
+		"_meta": map[string]interface{}{
+			"internalCopies": []interface{}{"background"},
+		},
+		"background": []interface{}{
+			map[string]interface{}{"name": "Acolyte", "source": "XPHB", "page": 178},
+			map[string]interface{}{"name": "Sage", "source": "XPHB", "page": 183},
+			map[string]interface{}{"name": "Soldier", "source": "XPHB", "page": 185},
+		},
+	}
+	dictionary := indexEntries("background", []map[string]interface{}{
+		{"origin_name": "Soldier", "origin_source": "XPHB", "name": "Солдат"},
+		{"origin_name": "Acolyte", "origin_source": "XPHB", "name": "Аколіт"},
+		{"origin_name": "Sage", "origin_source": "XPHB", "name": ""},
+	}).entries["background"]
+
+	translator := NewTranslator("", "", "")
+	expected, err := translator.applyTranslations("background", sourceData, dictionary)
+	if err != nil {
+		t.Fatalf("Failed to apply translations: %v", err)
+	}
+
+	sourceJSON, err := json.Marshal(sourceData)
+	if err != nil {
+		t.Fatalf("Failed to marshal source data: %v", err)
+	}
+
+	var out bytes.Buffer
+	result, err := translator.streamTranslations("background", bytes.NewReader(sourceJSON), &out, dictionary, nil)
+	if err != nil {
+		t.Fatalf("Failed to stream translations: %v", err)
+	}
+
+	var streamed, loaded map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &streamed); err != nil {
+		t.Fatalf("Streamed output is not valid JSON: %v\n%s", err, out.String())
+	}
+	expectedJSON, _ := json.Marshal(expected.data)
+	json.Unmarshal(expectedJSON, &loaded)
+
+	if !reflect.DeepEqual(streamed, loaded) {
+		t.Errorf("Expected streamed output to match loaded output\nstreamed: %v\nloaded: %v", streamed, loaded)
+	}
+
+	if !reflect.DeepEqual(result.matched, expected.matched) {
+		t.Errorf("Expected matched keys %v, got %v", expected.matched, result.matched)
+	}
+	if !reflect.DeepEqual(result.translated, expected.translated) || len(result.translated) != 2 {
+		t.Errorf("Expected translated keys %v without the scaffold, got %v", expected.translated, result.translated)
+	}
+}
+
+func TestTranslateStreamsLargeFiles(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_stream")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+	exportDir := filepath.Join(tempDir, "export")
+
+	writeJSONFile(t, filepath.Join(dataDir, "bestiary", "bestiary-xmm.json"), map[string]interface{}{
+		"monster": []interface{}{
+			map[string]interface{}{"name": "Goblin Warrior", "source": "XMM"},
+		},
+	})
+	writeJSONFile(t, filepath.Join(dictDir, "bestiary.json"), map[string]interface{}{
+		"monster": map[string]interface{}{"origin_name": "Goblin Warrior", "origin_source": "XMM", "name": "Гоблін-воїн"},
+	})
+
+	translator := NewTranslator(dataDir, dictDir, exportDir)
+	translator.SetCategories([]string{"monster"})
+	translator.SetOptions(Options{StreamThreshold: 1})
+	if err := translator.Translate(); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(exportDir, "bestiary", "bestiary-xmm.json"))
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	var output map[string]interface{}
+	if err := json.Unmarshal(content, &output); err != nil {
+		t.Fatalf("Failed to unmarshal output file: %v", err)
+	}
+
+	monsters := output["monster"].([]interface{})
+	if len(monsters) != 1 || monsters[0].(map[string]interface{})["name"] != "Гоблін-воїн" {
+		t.Errorf("Expected translated goblin, got %v", monsters)
+	}
+
+	temps, _ := filepath.Glob(filepath.Join(exportDir, "bestiary", "*.tmp"))
+	if len(temps) != 0 {
+		t.Errorf("Expected temporary outputs to be removed, got %v", temps)
+	}
+}
+
+func TestStreamedBuildCacheKeepsOnlyHashes(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test_stream_cache")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+	exportDir := filepath.Join(tempDir, "export")
+	outputPath := filepath.Join(exportDir, "bestiary", "bestiary-xmm.json")
+
+	writeJSONFile(t, filepath.Join(dataDir, "bestiary", "bestiary-xmm.json"), map[string]interface{}{
+		"_meta": map[string]interface{}{"internalCopies": []interface{}{"monster"}},
+		"monster": []interface{}{
+			map[string]interface{}{"name": "Goblin Warrior", "source": "XMM", "entries": []interface{}{"Sneaky <b>& quick</b>."}},
+			map[string]interface{}{"name": "Goblin Boss", "source": "XMM"},
+			map[string]interface{}{"name": "Orc", "source": "XMM"},
+		},
+	})
+	dictionary := []interface{}{
+		map[string]interface{}{"origin_name": "Goblin Warrior", "origin_source": "XMM", "name": "Гоблін-воїн", "entries": []interface{}{"Підступний <b>& швидкий</b>."}},
+		map[string]interface{}{"origin_name": "Orc", "origin_source": "XMM", "name": "Орк"},
+	}
+	writeJSONFile(t, filepath.Join(dictDir, "bestiary.json"), map[string]interface{}{"monster": dictionary})
+
+	build := func() []byte {
+		translator := NewTranslator(dataDir, dictDir, exportDir)
+		translator.SetCategories([]string{"monster"})
+		translator.SetOptions(Options{StreamThreshold: 1})
+		if err := translator.Translate(); err != nil {
+			t.Fatalf("Failed to translate: %v", err)
+		}
+		content, err := ioutil.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		return content
+	}
+	first := build()
+
+	// An unrelated dictionary change rebuilds the file from the previous export
+	writeJSONFile(t, filepath.Join(dictDir, "spells.json"), map[string]interface{}{
+		"spell": map[string]interface{}{"origin_name": "Light", "origin_source": "XPHB", "name": "Світло"},
+	})
+	if second := build(); !bytes.Equal(first, second) {
+		t.Errorf("Expected reused entities to give the same output\nfirst: %s\nsecond: %s", first, second)
+	}
+
+	translator := NewTranslator(dataDir, dictDir, exportDir)
+	cache := translator.loadCache()
+	if len(cache.Entities) != 2 {
+		t.Fatalf("Expected 2 cached entities, got %d", len(cache.Entities))
+	}
+	for key, entry := range cache.Entities {
+		if entry.InputHash == "" || len(entry.Output) != 0 {
+			t.Errorf("Expected only the input hash of streamed entity %s, got %+v", key, entry)
+		}
+	}
+
+	// A changed entry is merged again, the unchanged one after it still reused
+	dictionary[0].(map[string]interface{})["name"] = "Гоблін-вояк"
+	writeJSONFile(t, filepath.Join(dictDir, "bestiary.json"), map[string]interface{}{"monster": dictionary})
+	var output struct {
+		Monster []map[string]interface{} `json:"monster"`
+	}
+	if err := json.Unmarshal(build(), &output); err != nil {
+		t.Fatalf("Failed to unmarshal output file: %v", err)
+	}
+	monsters := output.Monster
+	if len(monsters) != 2 || monsters[0]["name"] != "Гоблін-вояк" || monsters[1]["name"] != "Орк" {
+		t.Errorf("Expected the changed goblin and the reused orc, got %v", monsters)
+	}
+}
+
+// benchmarkSource writes a bestiary-like file with the given number of entities
+// and a dictionary of fixed size, so memory differences come from the file alone
+func benchmarkSource(tb testing.TB, entities int) (string, map[string]map[string]interface{}) {
+	monsters := make([]interface{}, entities)
+	dictionary := make(map[string]map[string]interface{})
+	for i := range monsters {
+		name := fmt.Sprintf("Monster %d", i)
+		monsters[i] = map[string]interface{}{
+			"name":   name,
+			"source": "XMM",
+			"entries": []interface{}{
+				"A long description that makes every entity a few kilobytes in size. " + string(bytes.Repeat([]byte("x"), 2048)),
+			},
+		}
+		if i < 500 {
+			dictionary[name+"|XMM"] = map[string]interface{}{"origin_name": name, "origin_source": "XMM", "name": "Чудовисько"}
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{"monster": monsters})
+	if err != nil {
+		tb.Fatalf("Failed to marshal benchmark source: %v", err)
+	}
+
+	path := filepath.Join(tb.TempDir(), "bestiary-bench.json")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		tb.Fatalf("Failed to write benchmark source: %v", err)
+	}
+	return path, dictionary
+}
+
+// measurePeakHeap runs fn and returns the highest heap growth seen while it ran, in MB
+func measurePeakHeap(fn func()) float64 {
+	// Collect twice so pooled encoder buffers from building the source are released
+	runtime.GC()
+	runtime.GC()
+	var base runtime.MemStats
+	runtime.ReadMemStats(&base)
+
+	var peak uint64
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var stats runtime.MemStats
+		for {
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > peak {
+				peak = stats.HeapAlloc
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+
+	fn()
+	close(done)
+	wg.Wait()
+
+	if peak < base.HeapAlloc {
+		return 0
+	}
+	return float64(peak-base.HeapAlloc) / (1 << 20)
+}
+
+func benchmarkLoaded(b *testing.B, entities int) {
+	path, dictionary := benchmarkSource(b, entities)
+	translator := NewTranslator("", "", "")
+	b.ReportAllocs()
+	b.ResetTimer()
+	peak := measurePeakHeap(func() {
+		for i := 0; i < b.N; i++ {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var sourceData map[string]interface{}
+			if err := json.Unmarshal(data, &sourceData); err != nil {
+				b.Fatal(err)
+			}
+			result, err := translator.applyTranslations("monster", sourceData, dictionary)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := json.MarshalIndent(result.data, "", "    "); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.ReportMetric(peak, "peak-MB")
+}
+
+func benchmarkStreamed(b *testing.B, entities int) {
+	path, dictionary := benchmarkSource(b, entities)
+	translator := NewTranslator("", "", "")
+	b.ReportAllocs()
+	b.ResetTimer()
+	peak := measurePeakHeap(func() {
+		for i := 0; i < b.N; i++ {
+			in, err := os.Open(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_, err = translator.streamTranslations("monster", in, ioutil.Discard, dictionary, nil)
+			in.Close()
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.ReportMetric(peak, "peak-MB")
+}
+
+// streamedCachedBuild writes a streamed file and its dictionary and builds it
+// once with the build cache on. The returned function rebuilds it after
+// changing an unrelated dictionary file, so every translated entity is
+// reused from the previous export.
+func streamedCachedBuild(tb testing.TB, entities int) func(run int) {
+	path, dictionary := benchmarkSource(tb, entities)
+	tempDir := filepath.Dir(path)
+	dataDir := filepath.Join(tempDir, "data")
+	dictDir := filepath.Join(tempDir, "dictionary")
+	if err := os.MkdirAll(filepath.Join(dataDir, "bestiary"), 0755); err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.Rename(path, filepath.Join(dataDir, "bestiary", "bestiary-xmm.json")); err != nil {
+		tb.Fatal(err)
+	}
+
+	var entries []interface{}
+	for _, entry := range dictionary {
+		entries = append(entries, entry)
+	}
+	data, err := json.Marshal(map[string]interface{}{"monster": entries})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.MkdirAll(dictDir, 0755); err != nil {
+		tb.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dictDir, "bestiary.json"), data, 0644); err != nil {
+		tb.Fatal(err)
+	}
+
+	translator := NewTranslator(dataDir, dictDir, filepath.Join(tempDir, "export"))
+	translator.SetCategories([]string{"monster"})
+	translator.SetOptions(Options{StreamThreshold: 1, Jobs: 1})
+	build := func(run int) {
+		unrelated := fmt.Sprintf(`{"spell": {"origin_name": "Light", "origin_source": "XPHB", "name": "Світло %d"}}`, run)
+		if err := ioutil.WriteFile(filepath.Join(dictDir, "spells.json"), []byte(unrelated), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		if err := translator.Translate(); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	build(-1)
+	return build
+}
+
+func benchmarkStreamedCached(b *testing.B, entities int) {
+	build := streamedCachedBuild(b, entities)
+	b.ReportAllocs()
+	b.ResetTimer()
+	peak := measurePeakHeap(func() {
+		for i := 0; i < b.N; i++ {
+			build(i)
+		}
+	})
+	b.ReportMetric(peak, "peak-MB")
+}
+
+func TestStreamedCachedPeakStaysFlat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds large data files")
+	}
+
+	// The 10k file is ten times larger; a peak that grows with it means
+	// the cache or the previous export is held in memory
+	peaks := make([]float64, 2)
+	for i, entities := range []int{1000, 10000} {
+		build := streamedCachedBuild(t, entities)
+		peaks[i] = measurePeakHeap(func() { build(0) })
+	}
+	small, large := peaks[0], peaks[1]
+	if large > 2*small+1 {
+		t.Errorf("Expected the streamed peak heap to stay flat, got %.1f MB for 1k and %.1f MB for 10k entities", small, large)
+	}
+}
+
+// Compare peak-MB of loaded and streamed translations with
+// go test -run xxx -bench Translations ./translator
+func BenchmarkLoadedTranslations1k(b *testing.B)    { benchmarkLoaded(b, 1000) }
+func BenchmarkLoadedTranslations10k(b *testing.B)   { benchmarkLoaded(b, 10000) }
+func BenchmarkStreamedTranslations1k(b *testing.B)  { benchmarkStreamed(b, 1000) }
+func BenchmarkStreamedTranslations10k(b *testing.B) { benchmarkStreamed(b, 10000) }
+
+func BenchmarkStreamedCachedTranslations1k(b *testing.B)  { benchmarkStreamedCached(b, 1000) }
+func BenchmarkStreamedCachedTranslations10k(b *testing.B) { benchmarkStreamedCached(b, 10000) }
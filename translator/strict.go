@@ -0,0 +1,112 @@
+package translator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IssueKind identifies the type of a problem found while translating
+type IssueKind string
+
+const (
+	IssueInvalid     IssueKind = "invalid"
+	IssueOrphan      IssueKind = "orphan"
+	IssueStale       IssueKind = "stale"
+	IssueTagMismatch IssueKind = "tag-mismatch"
+
+	IssueCrossReference IssueKind = "cross-reference"
+)
+
+// Issue describes a single problem found while applying translations
+type Issue struct {
+	Kind    IssueKind `json:"kind"`
+	Key     string    `json:"key,omitempty"`
+	Message string    `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Key == "" {
+		return fmt.Sprintf("[%s] %s", i.Kind, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", i.Kind, i.Key, i.Message)
+}
+
+// EntityHash returns the hash dictionary entries store in origin_hash
+// to detect source entities that changed after translation
+func EntityHash(entity map[string]interface{}) string {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// checkEntry returns stale and tag mismatch issues for a matched dictionary entry
+func checkEntry(key string, sourceEntity, dictEntry map[string]interface{}) []Issue {
+	var issues []Issue
+
+	if originHash, ok := dictEntry["origin_hash"].(string); ok && originHash != "" {
+		if currentHash := EntityHash(sourceEntity); currentHash != originHash {
+			issues = append(issues, Issue{Kind: IssueStale, Key: key, Message: fmt.Sprintf("source changed since translation (origin_hash %s, current %s)", originHash, currentHash)})
+		}
+	}
+
+	dictEntries, exists := dictEntry["entries"]
+	if !exists {
+		return issues
+	}
+
+	sourceTargets := collectTagTargets(sourceEntity["entries"])
+	dictTargets := collectTagTargets(dictEntries)
+
+	var missing, extra []string
+	for target, count := range sourceTargets {
+		if dictTargets[target] < count {
+			missing = append(missing, target)
+		}
+	}
+	for target, count := range dictTargets {
+		if sourceTargets[target] < count {
+			extra = append(extra, target)
+		}
+	}
+	if len(missing) == 0 && len(extra) == 0 {
+		return issues
+	}
+
+	sort.Strings(missing)
+	sort.Strings(extra)
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, "missing {@"+strings.Join(missing, "}, {@")+"}")
+	}
+	if len(extra) > 0 {
+		parts = append(parts, "unexpected {@"+strings.Join(extra, "}, {@")+"}")
+	}
+	return append(issues, Issue{Kind: IssueTagMismatch, Key: key, Message: strings.Join(parts, "; ")})
+}
+
+// StrictError returns an error listing the issues of the last run that are
+// fatal with the current options, or nil
+func (t *Translator) StrictError() error {
+	return t.strictError()
+}
+
+// strictError returns an error listing the issues that are fatal with the current options
+func (t *Translator) strictError() error {
+	var fatal []string
+	for _, issue := range t.issues {
+		if t.options.failsOn(issue.Kind) {
+			fatal = append(fatal, issue.String())
+		}
+	}
+	if len(fatal) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d issue(s) found:\n  %s", len(fatal), strings.Join(fatal, "\n  "))
+}
@@ -0,0 +1,108 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+)
+
+func strictTestSource() map[string]interface{} {
+	return map[string]interface{}{
+		"background": []interface{}{
+			map[string]interface{}{
+				"name":   "Acolyte",
+				"source": "XPHB",
+				"entries": []interface{}{
+					"{@feat Magic Initiate|XPHB} (Cleric)",
+				},
+			},
+		},
+	}
+}
+
+func TestApplyTranslationsRecordsIssues(t *testing.T) {
+	dictionaryEntries := []map[string]interface{}{
+		{
+			"name": "Entry without origin",
+		},
+		{
+			"origin_name":   "Sage",
+			"origin_source": "XPHB",
+			"name":          "Мудрець",
+		},
+		{
+			"origin_name":   "Acolyte",
+			"origin_source": "XPHB",
+			"origin_hash":   "0000000000000000",
+			"name":          "Аколіт",
+			"entries": []interface{}{
+				"{@feat Magic Initiate|PHB} (Клірик)",
+			},
+		},
+	}
+
+	translator := NewTranslator("", "", "")
+	index := indexEntries("background", dictionaryEntries)
+	result, err := translator.applyTranslations("background", strictTestSource(), index.entries["background"])
+	if err != nil {
+		t.Fatalf("Failed to apply translations: %v", err)
+	}
+
+	category, _ := LookupCategory("background")
+	translator.collectIssues([]Category{category}, []*fileJob{{category: category, result: result}}, index)
+
+	kinds := make(map[IssueKind]int)
+	for _, issue := range translator.Issues() {
+		kinds[issue.Kind]++
+	}
+
+	for _, kind := range []IssueKind{IssueInvalid, IssueOrphan, IssueStale, IssueTagMismatch} {
+		if kinds[kind] != 1 {
+			t.Errorf("Expected 1 %s issue, got %d", kind, kinds[kind])
+		}
+	}
+
+	// Issues are only warnings without strict options
+	if err := translator.strictError(); err != nil {
+		t.Errorf("Expected no strict error without options, got %v", err)
+	}
+
+	translator.SetOptions(Options{FailOnOrphans: true})
+	err = translator.strictError()
+	if err == nil {
+		t.Fatalf("Expected strict error with FailOnOrphans")
+	}
+
+	if !strings.Contains(err.Error(), "Sage|XPHB") || strings.Contains(err.Error(), "Acolyte|XPHB") {
+		t.Errorf("Expected only the orphan to be reported, got %v", err)
+	}
+}
+
+func TestApplyTranslationsMatchingHash(t *testing.T) {
+	sourceData := strictTestSource()
+	sourceEntity := sourceData["background"].([]interface{})[0].(map[string]interface{})
+
+	dictionaryEntries := []map[string]interface{}{
+		{
+			"origin_name":   "Acolyte",
+			"origin_source": "XPHB",
+			"origin_hash":   EntityHash(sourceEntity),
+			"name":          "Аколіт",
+			"entries": []interface{}{
+				"{@feat Magic Initiate|xphb} (Клірик)",
+			},
+		},
+	}
+
+	translator := NewTranslator("", "", "")
+	translator.SetOptions(StrictOptions())
+	index := indexEntries("background", dictionaryEntries)
+	result, err := translator.applyTranslations("background", sourceData, index.entries["background"])
+	if err != nil {
+		t.Fatalf("Failed to apply translations: %v", err)
+	}
+	translator.issues = result.issues
+
+	if err := translator.strictError(); err != nil {
+		t.Errorf("Expected no strict error, got %v", err)
+	}
+}
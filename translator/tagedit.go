@@ -0,0 +1,247 @@
+package translator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// formattingTags are the tags whose arguments are text to translate rather than a link target
+var formattingTags = map[string]bool{
+	"b": true, "bold": true, "i": true, "italic": true, "s": true, "strike": true,
+	"u": true, "underline": true, "sup": true, "sub": true, "kbd": true, "code": true,
+	"note": true, "tip": true, "highlight": true,
+}
+
+// LockedSpan is a part of a tag translators must not edit, as byte offsets
+// into the text: the tag name, the link target and the closing brace
+type LockedSpan struct {
+	Start, End       int
+	TagStart, TagEnd int // the whole tag, which may still be removed at once
+}
+
+// LockedSpans returns the locked spans of every tag in text, including tags
+// nested in formatting tags
+func LockedSpans(text string) []LockedSpan {
+	return lockedSpans(text, 0)
+}
+
+// lockedSpans collects the locked spans of the tags of text, which starts at offset
+func lockedSpans(text string, offset int) []LockedSpan {
+	var spans []LockedSpan
+	for _, tag := range ParseTags(text) {
+		start, end := offset+tag.Start, offset+tag.End
+
+		// Arguments start after the name and the blanks following it
+		argsStart := tag.Start + 2 + len(tag.Name)
+		for argsStart < tag.End-1 && (text[argsStart] == ' ' || text[argsStart] == '\t') {
+			argsStart++
+		}
+
+		if formattingTags[tag.Name] {
+			spans = append(spans, LockedSpan{Start: start, End: offset + argsStart, TagStart: start, TagEnd: end})
+			spans = append(spans, lockedSpans(text[argsStart:tag.End-1], offset+argsStart)...)
+		} else {
+			// The target and source are locked; the display text after them is not
+			lockEnd := argsStart
+			for i := 0; i < len(tag.Parts) && i < 2; i++ {
+				if i > 0 {
+					lockEnd++
+				}
+				lockEnd += len(tag.Parts[i])
+			}
+			spans = append(spans, LockedSpan{Start: start, End: offset + lockEnd, TagStart: start, TagEnd: end})
+		}
+		spans = append(spans, LockedSpan{Start: end - 1, End: end, TagStart: start, TagEnd: end})
+	}
+	return spans
+}
+
+// TagEditAllowed reports whether changing before into after leaves the locked
+// spans of before intact. Removing whole tags is allowed, as is typing right
+// before or after a locked span.
+func TagEditAllowed(before, after string) bool {
+	// The edit replaced before[prefix:len(before)-suffix]
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	for prefix > 0 && !utf8.RuneStart(before[prefix]) {
+		prefix--
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	changedEnd := len(before) - suffix
+
+	for _, span := range LockedSpans(before) {
+		if prefix <= span.TagStart && changedEnd >= span.TagEnd {
+			continue
+		}
+		if prefix == changedEnd {
+			if span.Start < prefix && prefix < span.End {
+				return false
+			}
+		} else if prefix < span.End && changedEnd > span.Start {
+			return false
+		}
+	}
+	return true
+}
+
+// TagProblem is a syntax error in the tags of a text
+type TagProblem struct {
+	Offset  int // byte offset of the offending brace
+	Message string
+}
+
+// CheckTagSyntax returns unbalanced braces and tags without a name in text
+func CheckTagSyntax(text string) []TagProblem {
+	var problems []TagProblem
+	var open []int
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			open = append(open, i)
+			if strings.HasPrefix(text[i:], "{@") && (i+2 == len(text) || strings.ContainsRune(" \t|}", rune(text[i+2]))) {
+				problems = append(problems, TagProblem{Offset: i, Message: "tag without a name"})
+			}
+		case '}':
+			if len(open) == 0 {
+				problems = append(problems, TagProblem{Offset: i, Message: "closing brace without a tag"})
+				continue
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	for _, i := range open {
+		problems = append(problems, TagProblem{Offset: i, Message: fmt.Sprintf("unclosed tag %s", tagPrefix(text[i:]))})
+	}
+	sort.Slice(problems, func(a, b int) bool { return problems[a].Offset < problems[b].Offset })
+	return problems
+}
+
+// tagPrefix returns the opening of a tag up to its name, for messages
+func tagPrefix(text string) string {
+	end := strings.IndexAny(text, " \t|}")
+	if end < 0 || end > 24 {
+		end = len(text)
+		if end > 24 {
+			end = 24
+		}
+	}
+	return text[:end]
+}
+
+// maxCompletions limits the suggestions of a completion
+const maxCompletions = 8
+
+// TagCompleter suggests tag names and link targets known from source data
+type TagCompleter struct {
+	names   []string
+	targets map[string][]string // tag name to "Name|SOURCE" targets
+}
+
+// NewTagCompleter collects the tag names and link targets of the linked
+// categories and of the tags used in the entries' source text
+func NewTagCompleter(entries []Entry) *TagCompleter {
+	names := make(map[string]bool)
+	targets := make(map[string]map[string]bool)
+	addTarget := func(tagName, target string) {
+		if targets[tagName] == nil {
+			targets[tagName] = make(map[string]bool)
+		}
+		targets[tagName][target] = true
+	}
+
+	for name := range formattingTags {
+		names[name] = true
+	}
+	for _, category := range categories {
+		for _, tagName := range category.Tags {
+			names[tagName] = true
+		}
+	}
+	for _, entry := range entries {
+		name, _ := entry.Source["name"].(string)
+		source, _ := entry.Source["source"].(string)
+		for _, category := range categories {
+			if category.Key != entry.Category {
+				continue
+			}
+			for _, tagName := range category.Tags {
+				addTarget(tagName, name+"|"+source)
+			}
+		}
+
+		walkStrings(entry.Source["entries"], func(s string) {
+			for _, tag := range ParseTags(s) {
+				names[tag.Name] = true
+				if !formattingTags[tag.Name] && len(tag.Parts) > 1 {
+					addTarget(tag.Name, tag.Parts[0]+"|"+tag.Parts[1])
+				}
+			}
+		})
+	}
+
+	c := &TagCompleter{targets: make(map[string][]string)}
+	for name := range names {
+		c.names = append(c.names, name)
+	}
+	sort.Strings(c.names)
+	for tagName, set := range targets {
+		for target := range set {
+			c.targets[tagName] = append(c.targets[tagName], target)
+		}
+		// Targets are sorted alphabetically by name, ignoring case, then by source;
+		// a name sorts before the longer names it is a prefix of, like "Light"
+		// before "Lightning Bolt"
+		list := c.targets[tagName]
+		sort.Slice(list, func(i, j int) bool {
+			a, b := strings.ToLower(list[i]), strings.ToLower(list[j])
+			nameA, nameB := strings.SplitN(a, "|", 2)[0], strings.SplitN(b, "|", 2)[0]
+			if nameA != nameB {
+				return nameA < nameB
+			}
+			return a < b
+		})
+	}
+	return c
+}
+
+// Complete returns suggestions for the tag being typed before caret (a byte
+// offset): tag names right after "{@", link targets in the first argument.
+// Suggestions replace text[start:caret].
+func (c *TagCompleter) Complete(text string, caret int) (int, []string) {
+	open := strings.LastIndex(text[:caret], "{@")
+	if open < 0 || strings.ContainsAny(text[open:caret], "}|") {
+		return caret, nil
+	}
+
+	typed := text[open+2 : caret]
+	space := strings.IndexAny(typed, " \t")
+	if space < 0 {
+		return open + 2, completions(c.names, typed)
+	}
+	if strings.ContainsAny(typed[space+1:], "{") {
+		return caret, nil
+	}
+	return open + 2 + space + 1, completions(c.targets[typed[:space]], typed[space+1:])
+}
+
+// completions returns the candidates starting with prefix, ignoring case
+func completions(candidates []string, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) && candidate != prefix {
+			matches = append(matches, candidate)
+			if len(matches) == maxCompletions {
+				break
+			}
+		}
+	}
+	return matches
+}
@@ -0,0 +1,102 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLockedSpans(t *testing.T) {
+	text := "Take {@item Book|XPHB|Book (prayers)} and {@b read {@spell Light|XPHB}}."
+	spans := LockedSpans(text)
+
+	var locked []string
+	for _, span := range spans {
+		locked = append(locked, text[span.Start:span.End])
+	}
+	expected := []string{"{@item Book|XPHB", "}", "{@b ", "{@spell Light|XPHB", "}", "}"}
+	if strings.Join(locked, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected locked spans %q, got %q", expected, locked)
+	}
+}
+
+func TestTagEditAllowed(t *testing.T) {
+	before := "Take {@item Book|XPHB|Book (prayers)} now."
+
+	tests := []struct {
+		after    string
+		expected bool
+	}{
+		{"Візьміть {@item Book|XPHB|Book (prayers)} now.", true},
+		{"Take {@item Book|XPHB|Книга (молитви)} now.", true},
+		{"Take {@item Book|XPHB|Book (prayers)} зараз.", true},
+		{"Take {@item Книга|XPHB|Book (prayers)} now.", false},
+		{"Take {@items Book|XPHB|Book (prayers)} now.", false},
+		{"Take {@item Book|XPHB|Book (prayers) now.", false},
+		{"Take  now.", true},
+		{"Take {@item Book|XPHB|Book (prayers)}{@b !} now.", true},
+	}
+
+	for _, test := range tests {
+		if allowed := TagEditAllowed(before, test.after); allowed != test.expected {
+			t.Errorf("Expected TagEditAllowed to be %v for %q, got %v", test.expected, test.after, allowed)
+		}
+	}
+
+	// A display text can be added right before the closing brace of a tag without one
+	if !TagEditAllowed("{@spell Light|XPHB}", "{@spell Light|XPHB|Світло}") {
+		t.Errorf("Expected a display text to be addable")
+	}
+}
+
+func TestCheckTagSyntax(t *testing.T) {
+	if problems := CheckTagSyntax("{@b bold {@spell Light|XPHB}} text"); len(problems) != 0 {
+		t.Errorf("Expected balanced tags to pass, got %v", problems)
+	}
+
+	problems := CheckTagSyntax("} {@item Book|XPHB {@ x}")
+	if len(problems) != 3 {
+		t.Fatalf("Expected 3 problems, got %v", problems)
+	}
+	if problems[0].Offset != 0 || problems[0].Message != "closing brace without a tag" {
+		t.Errorf("Expected a stray closing brace first, got %v", problems[0])
+	}
+	if problems[1].Offset != 2 || problems[1].Message != "unclosed tag {@item" {
+		t.Errorf("Expected the unclosed item tag second, got %v", problems[1])
+	}
+	if problems[2].Message != "tag without a name" {
+		t.Errorf("Expected a tag without a name last, got %v", problems[2])
+	}
+}
+
+func TestTagCompleter(t *testing.T) {
+	completer := NewTagCompleter([]Entry{
+		{Category: "spell", Source: map[string]interface{}{"name": "Light", "source": "XPHB"}},
+		{Category: "spell", Source: map[string]interface{}{"name": "Lightning Bolt", "source": "XPHB"}},
+		{Category: "background", Source: map[string]interface{}{
+			"name": "Acolyte", "source": "XPHB",
+			"entries": []interface{}{"Carry a {@item Book|XPHB|book}."},
+		}},
+	})
+
+	text := "Cast {@sp"
+	start, suggestions := completer.Complete(text, len(text))
+	if start != 7 || len(suggestions) == 0 || suggestions[0] != "spell" {
+		t.Errorf("Expected the spell tag name from offset 7, got %d %v", start, suggestions)
+	}
+
+	text = "Cast {@spell lig"
+	start, suggestions = completer.Complete(text, len(text))
+	if start != 13 || len(suggestions) != 2 || suggestions[0] != "Light|XPHB" || suggestions[1] != "Lightning Bolt|XPHB" {
+		t.Errorf("Expected both spell targets from offset 13, got %d %v", start, suggestions)
+	}
+
+	text = "{@item b"
+	if _, suggestions = completer.Complete(text, len(text)); len(suggestions) != 1 || suggestions[0] != "Book|XPHB" {
+		t.Errorf("Expected the item target used in the source text, got %v", suggestions)
+	}
+
+	text = "{@spell Light|XPHB} li"
+	if _, suggestions = completer.Complete(text, len(text)); len(suggestions) != 0 {
+		t.Errorf("Expected no suggestions outside a tag, got %v", suggestions)
+	}
+}
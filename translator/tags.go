@@ -0,0 +1,110 @@
+package translator
+
+import (
+	"strings"
+)
+
+// Tag is a single inline 5etools tag such as {@item Book|XPHB|Book (prayers)}
+type Tag struct {
+	Name  string   // tag name without the leading @, e.g. "item"
+	Parts []string // pipe separated tag arguments
+	Start int      // byte offset of the opening brace
+	End   int      // byte offset just past the closing brace
+}
+
+// Target returns the normalized link target of the tag (name|source)
+func (tag Tag) Target() string {
+	if len(tag.Parts) == 0 {
+		return ""
+	}
+	target := strings.ToLower(strings.TrimSpace(tag.Parts[0]))
+	if len(tag.Parts) > 1 {
+		target += "|" + strings.ToLower(strings.TrimSpace(tag.Parts[1]))
+	}
+	return target
+}
+
+// Display returns the text 5etools shows for the tag
+func (tag Tag) Display() string {
+	if len(tag.Parts) > 2 && tag.Parts[2] != "" {
+		return tag.Parts[2]
+	}
+	if len(tag.Parts) > 0 {
+		return tag.Parts[0]
+	}
+	return ""
+}
+
+// ParseTags returns the top level inline tags found in text
+func ParseTags(text string) []Tag {
+	var tags []Tag
+
+	for i := 0; i < len(text); i++ {
+		if !strings.HasPrefix(text[i:], "{@") {
+			continue
+		}
+
+		// Find the matching closing brace, allowing nested tags
+		depth := 0
+		end := -1
+		for j := i; j < len(text); j++ {
+			if text[j] == '{' {
+				depth++
+			} else if text[j] == '}' {
+				depth--
+				if depth == 0 {
+					end = j
+					break
+				}
+			}
+		}
+		if end < 0 {
+			break
+		}
+
+		body := text[i+2 : end]
+		name := body
+		args := ""
+		if space := strings.IndexAny(body, " \t"); space >= 0 {
+			name = body[:space]
+			args = strings.TrimSpace(body[space+1:])
+		}
+
+		tag := Tag{Name: name, Start: i, End: end + 1}
+		if args != "" {
+			tag.Parts = strings.Split(args, "|")
+		}
+		tags = append(tags, tag)
+
+		i = end
+	}
+
+	return tags
+}
+
+// walkStrings calls fn for every string leaf of a decoded JSON value
+func walkStrings(value interface{}, fn func(string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v)
+	case []interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	}
+}
+
+// collectTagTargets counts tag link targets found in a decoded JSON value
+func collectTagTargets(value interface{}) map[string]int {
+	targets := make(map[string]int)
+	walkStrings(value, func(s string) {
+		for _, tag := range ParseTags(s) {
+			targets[tag.Name+" "+tag.Target()]++
+		}
+	})
+	return targets
+}
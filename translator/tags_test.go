@@ -0,0 +1,42 @@
+package translator
+
+import (
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tags := ParseTags("Choose {@item Book|XPHB|Book (prayers)} and {@skill Insight|XPHB}")
+
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got %d", len(tags))
+	}
+
+	if tags[0].Name != "item" {
+		t.Errorf("Expected tag name 'item', got '%s'", tags[0].Name)
+	}
+
+	if tags[0].Target() != "book|xphb" {
+		t.Errorf("Expected target 'book|xphb', got '%s'", tags[0].Target())
+	}
+
+	if tags[0].Display() != "Book (prayers)" {
+		t.Errorf("Expected display 'Book (prayers)', got '%s'", tags[0].Display())
+	}
+
+	if tags[1].Display() != "Insight" {
+		t.Errorf("Expected display 'Insight', got '%s'", tags[1].Display())
+	}
+}
+
+func TestParseTagsNested(t *testing.T) {
+	text := "{@note see {@spell Light|XPHB}} after"
+	tags := ParseTags(text)
+
+	if len(tags) != 1 {
+		t.Fatalf("Expected 1 top level tag, got %d", len(tags))
+	}
+
+	if text[tags[0].End:] != " after" {
+		t.Errorf("Expected tag to end before ' after', got '%s'", text[tags[0].End:])
+	}
+}
@@ -1,6 +1,7 @@
 package translator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -10,9 +11,27 @@ import (
 
 // Translator handles the translation process
 type Translator struct {
-	dataPath       string
-	dictionaryPath string
-	exportPath     string
+	dataPath          string
+	dictionaryPath    string
+	extraDictionaries []string
+	fallbacks         []dictionaryLayer
+	locale            string
+	exportPath        string
+	categories        []string
+	options           Options
+	issues            []Issue
+	cache             *buildCache
+	nextCache         *buildCache
+	optionsDigest     string // optionsHash of the running translation
+	references        *referenceIndex
+	index             *dictionaryIndex   // dictionary of the last Entries call, updated by MergeEntry
+	layerIndexes      []*dictionaryIndex // layers index was composed from, the translator's own first
+	layerNames        []string
+	skipped           []string
+	outputs           []string
+	origins           []StringOrigin
+	stats             Stats
+	progress          func(Progress)
 }
 
 // NewTranslator creates a new translator instance
@@ -21,82 +40,209 @@ func NewTranslator(dataPath, dictionaryPath, exportPath string) *Translator {
 		dataPath:       dataPath,
 		dictionaryPath: dictionaryPath,
 		exportPath:     exportPath,
+		categories:     []string{"background"},
 	}
 }
 
-// Translate processes all background files and applies translations
+// AddDictionaryPath adds another dictionary directory read after the previous ones
+func (t *Translator) AddDictionaryPath(dictionaryPath string) {
+	t.extraDictionaries = append(t.extraDictionaries, dictionaryPath)
+}
+
+// SetLocale names the layer of the translator's own dictionaries in origin reports
+func (t *Translator) SetLocale(locale string) {
+	t.locale = locale
+}
+
+// AddFallback adds a dictionary layer used for entities and strings the previous layers do not translate
+func (t *Translator) AddFallback(name string, dictionaryPaths ...string) {
+	t.fallbacks = append(t.fallbacks, dictionaryLayer{name: name, paths: dictionaryPaths})
+}
+
+// SetCategories selects the entity categories to translate
+func (t *Translator) SetCategories(categories []string) {
+	t.categories = append([]string(nil), categories...)
+}
+
+// SetOptions configures which issues make the translation fail
+func (t *Translator) SetOptions(options Options) {
+	t.options = options
+}
+
+// Issues returns the issues found by the last translation run
+func (t *Translator) Issues() []Issue {
+	return t.issues
+}
+
+// Skipped returns the output files the last run reused from the build cache
+func (t *Translator) Skipped() []string {
+	return t.skipped
+}
+
+// Outputs returns the files the last run wrote or reused, relative to the export directory
+func (t *Translator) Outputs() []string {
+	return t.outputs
+}
+
+// ExportPath returns the directory translated files are written to
+func (t *Translator) ExportPath() string {
+	return t.exportPath
+}
+
+// Origins returns the dictionary layer of every string the last run translated
+func (t *Translator) Origins() []StringOrigin {
+	return t.origins
+}
+
+// Stats returns the statistics of the last translation run
+func (t *Translator) Stats() Stats {
+	return t.stats
+}
+
+// Translate processes the data files of all enabled categories and applies translations
 func (t *Translator) Translate() error {
-	// Read dictionary data
-	dictionaryEntries, err := t.loadDictionaryEntries()
+	return t.TranslateContext(context.Background())
+}
+
+// TranslateContext is Translate with cancellation. A run canceled before
+// the write stage leaves the export directory untouched.
+func (t *Translator) TranslateContext(ctx context.Context) error {
+	t.issues = nil
+	t.skipped = nil
+	t.outputs = nil
+	t.origins = nil
+	t.stats = Stats{}
+
+	if err := ValidateNamingPattern(t.options.NamingPattern); err != nil {
+		return err
+	}
+
+	enabled, err := t.enabledCategories()
+	if err != nil {
+		return err
+	}
+
+	jobs, err := t.planJobs(enabled)
+	if err != nil {
+		return fmt.Errorf("failed to load source data: %w", err)
+	}
+
+	// Read dictionary data once; workers share the index read-only
+	index, err := t.loadDictionaryIndex()
 	if err != nil {
 		return fmt.Errorf("failed to load dictionary data: %w", err)
 	}
 
+	// Entries keeps its reference index for MergeEntry, so it is restored after the run
+	defer func(references *referenceIndex) { t.references = references }(t.references)
+	t.references = newReferenceIndex(index, t.options.NamingPattern)
+
+	dictionaryHash, err := t.dictionaryHash()
+	if err != nil {
+		return fmt.Errorf("failed to hash dictionary data: %w", err)
+	}
+
+	// Load the build cache
+	t.cache = t.loadCache()
+	t.nextCache = newBuildCache()
+	t.optionsDigest = t.optionsHash()
+	defer func() {
+		t.cache = nil
+		t.nextCache = nil
+		t.optionsDigest = ""
+	}()
+
+	defer removeStreamed(jobs)
+
 	// Read source data
-	sourceData, err := t.loadSourceData()
+	err = runStage(ctx, jobs, t.workers(), func(job *fileJob) error {
+		return t.loadStage(job, dictionaryHash)
+	}, t.stageProgress(StageLoad, len(jobs)))
 	if err != nil {
-		return fmt.Errorf("failed to load source data: %w", err)
+		return err
 	}
 
 	// Apply translations
-	translatedData, err := t.applyTranslations(sourceData, dictionaryEntries)
+	err = runStage(ctx, jobs, t.workers(), func(job *fileJob) error {
+		return t.mergeStage(job, index)
+	}, t.stageProgress(StageMerge, len(jobs)))
 	if err != nil {
-		return fmt.Errorf("failed to apply translations: %w", err)
+		return err
+	}
+
+	t.collectIssues(enabled, jobs, index)
+	t.stats = collectStats(jobs, t.issues)
+
+	// Fail before writing anything if strict checks found problems
+	if err := t.strictError(); err != nil {
+		return fmt.Errorf("strict mode: %w", err)
 	}
 
 	// Write translated data to export directory
-	err = t.writeTranslatedData(translatedData)
+	err = runStage(ctx, jobs, t.workers(), t.writeStage, t.stageProgress(StageWrite, len(jobs)))
 	if err != nil {
-		return fmt.Errorf("failed to write translated data: %w", err)
+		return err
 	}
 
-	return nil
-}
+	for _, job := range jobs {
+		t.outputs = append(t.outputs, job.file)
+		if job.skipped {
+			fmt.Printf("Skipped unchanged %s\n", job.file)
+			t.skipped = append(t.skipped, job.file)
+		}
+	}
 
-// loadDictionaryEntries loads all dictionary files and returns a slice of entries
-func (t *Translator) loadDictionaryEntries() ([]map[string]interface{}, error) {
-	files, err := filepath.Glob(filepath.Join(t.dictionaryPath, "*.json"))
+	err = t.saveCache(t.nextCache)
 	if err != nil {
-		return nil, fmt.Errorf("failed to glob dictionary files: %w", err)
+		return err
 	}
 
-	var allEntries []map[string]interface{}
+	return nil
+}
 
-	for _, file := range files {
-		data, err := ioutil.ReadFile(file)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read dictionary file %s: %w", file, err)
+// enabledCategories returns the categories selected for translation
+func (t *Translator) enabledCategories() ([]Category, error) {
+	var enabled []Category
+	for _, key := range t.categories {
+		category, ok := LookupCategory(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown category %q", key)
 		}
+		enabled = append(enabled, category)
+	}
+	return enabled, nil
+}
 
-		var dictData map[string]interface{}
-		err = json.Unmarshal(data, &dictData)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal dictionary file %s: %w", file, err)
+// collectIssues gathers dictionary, per-file and orphan issues in a stable order
+// together with the origins of the matched translations
+func (t *Translator) collectIssues(enabled []Category, jobs []*fileJob, index *dictionaryIndex) {
+	t.issues = append(t.issues, index.issues...)
+
+	matched := make(map[string]bool)
+	for _, job := range jobs {
+		t.issues = append(t.issues, job.result.issues...)
+		for _, key := range job.result.matched {
+			matched[job.category.Key+"|"+key] = true
+			t.origins = append(t.origins, index.origins[job.category.Key][key]...)
 		}
+	}
 
-		// Extract background entries from the dictionary
-		if backgroundEntry, exists := dictData["background"]; exists {
-			switch v := backgroundEntry.(type) {
-			case map[string]interface{}:
-				// Single object format
-				allEntries = append(allEntries, v)
-			case []interface{}:
-				// Array format
-				for _, item := range v {
-					if backgroundMap, ok := item.(map[string]interface{}); ok {
-						allEntries = append(allEntries, backgroundMap)
-					}
-				}
+	for _, category := range enabled {
+		for _, key := range index.keys[category.Key] {
+			if !matched[category.Key+"|"+key] {
+				t.issues = append(t.issues, Issue{Kind: IssueOrphan, Key: key, Message: fmt.Sprintf("no matching source %s", category.Key)})
 			}
 		}
 	}
 
-	return allEntries, nil
+	for _, issue := range t.issues {
+		fmt.Printf("Warning: %s\n", issue)
+	}
 }
 
-// loadSourceData loads the source background data
-func (t *Translator) loadSourceData() (map[string]interface{}, error) {
-	data, err := ioutil.ReadFile(filepath.Join(t.dataPath, "backgrounds.json"))
+// loadSourceData loads a source data file relative to the data directory
+func (t *Translator) loadSourceData(file string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(filepath.Join(t.dataPath, file))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read source data: %w", err)
 	}
@@ -110,96 +256,159 @@ func (t *Translator) loadSourceData() (map[string]interface{}, error) {
 	return sourceData, nil
 }
 
-// applyTranslations applies dictionary translations to source data
-func (t *Translator) applyTranslations(sourceData map[string]interface{}, dictionaryEntries []map[string]interface{}) (map[string]interface{}, error) {
+// mergeResult is the outcome of applying translations to one data file
+type mergeResult struct {
+	data       map[string]interface{}
+	sources    int      // number of source entities of the category
+	matched    []string // dictionary keys that matched a source entity
+	translated []string // matched keys that translate anything, in output order
+	issues     []Issue
+}
+
+// applyTranslations applies dictionary translations to the category entities of source data
+func (t *Translator) applyTranslations(category string, sourceData map[string]interface{}, dictionary map[string]map[string]interface{}) (*mergeResult, error) {
 	// Create a copy of source data
-	translatedData := make(map[string]interface{})
+	result := &mergeResult{data: make(map[string]interface{})}
 	for k, v := range sourceData {
-		translatedData[k] = v
+		result.data[k] = v
 	}
 
-	// Get the backgrounds array from source data
-	backgroundsInterface, exists := sourceData["background"]
+	// Get the entity array from source data
+	entitiesInterface, exists := sourceData[category]
 	if !exists {
-		return translatedData, fmt.Errorf("no 'background' field found in source data")
+		return result, fmt.Errorf("no '%s' field found in source data", category)
 	}
 
-	backgroundsArray, ok := backgroundsInterface.([]interface{})
+	entitiesArray, ok := entitiesInterface.([]interface{})
 	if !ok {
-		return translatedData, fmt.Errorf("'background' field is not an array")
-	}
-
-	fmt.Printf("Found %d source backgrounds\n", len(backgroundsArray))
-	fmt.Printf("Found %d dictionary entries\n", len(dictionaryEntries))
-
-	// Create a map for quick lookup of source backgrounds
-	sourceBackgroundsMap := make(map[string]interface{})
-	for _, bg := range backgroundsArray {
-		if bgMap, ok := bg.(map[string]interface{}); ok {
-			name, nameOk := bgMap["name"].(string)
-			source, sourceOk := bgMap["source"].(string)
-			if nameOk && sourceOk {
-				key := name + "|" + source
-				sourceBackgroundsMap[key] = bgMap
-				fmt.Printf("Source background: %s|%s\n", name, source)
-			}
-		}
+		return result, fmt.Errorf("'%s' field is not an array", category)
 	}
 
-	// Process each dictionary entry
-	var translatedBackgrounds []interface{}
-	for _, dictEntry := range dictionaryEntries {
-		originName, originNameOk := dictEntry["origin_name"].(string)
-		originSource, originSourceOk := dictEntry["origin_source"].(string)
+	result.sources = len(entitiesArray)
+	fmt.Printf("Found %d source %s entities\n", len(entitiesArray), category)
 
-		if !originNameOk || !originSourceOk {
-			fmt.Printf("Skipping dictionary entry without proper origin info\n")
-			continue // Skip entries without proper origin info
+	// Process each source entity that has a dictionary entry
+	var translatedEntities []interface{}
+	for _, entity := range entitiesArray {
+		sourceEntityMap, ok := entity.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, nameOk := sourceEntityMap["name"].(string)
+		source, sourceOk := sourceEntityMap["source"].(string)
+		if !nameOk || !sourceOk {
+			continue
 		}
 
-		fmt.Printf("Dictionary entry: %s|%s\n", originName, originSource)
+		key := name + "|" + source
+		dictEntry, exists := dictionary[key]
+		if !exists {
+			continue
+		}
 
-		// Find matching source background
-		key := originName + "|" + originSource
-		if sourceBackground, exists := sourceBackgroundsMap[key]; exists {
-			fmt.Printf("Found match for: %s\n", key)
-			// Create a copy of the source background
-			sourceBgMap := sourceBackground.(map[string]interface{})
-			translatedBg := make(map[string]interface{})
+		result.matched = append(result.matched, key)
+		result.issues = append(result.issues, checkEntry(key, sourceEntityMap, dictEntry)...)
+		result.issues = append(result.issues, t.referenceIssues(key, dictEntry)...)
+		if !hasTranslation(dictEntry) {
+			continue
+		}
+		result.translated = append(result.translated, key)
 
-			// Copy all properties from source
-			for k, v := range sourceBgMap {
-				translatedBg[k] = v
-			}
+		translatedEntity, err := t.mergeEntity(category+"|"+key, sourceEntityMap, dictEntry)
+		if err != nil {
+			return result, fmt.Errorf("failed to merge %s %s: %w", category, key, err)
+		}
 
-			// Apply translations from dictionary
-			if translatedName, exists := dictEntry["name"]; exists {
-				translatedBg["name"] = translatedName
-			}
+		translatedEntities = append(translatedEntities, translatedEntity)
+	}
 
-			// Apply entries translation if present
-			if dictEntries, exists := dictEntry["entries"]; exists {
-				translatedBg["entries"] = dictEntries
-			}
+	fmt.Printf("Created %d translated %s entities\n", len(translatedEntities), category)
 
-			translatedBackgrounds = append(translatedBackgrounds, translatedBg)
-		} else {
-			fmt.Printf("No match found for: %s\n", key)
+	// Update the translated data with the processed entities
+	result.data[category] = translatedEntities
+
+	return result, nil
+}
+
+// mergeEntity applies a dictionary entry to a copy of its source entity,
+// reusing the cached result when neither input changed
+func (t *Translator) mergeEntity(cacheKey string, sourceEntity, dictEntry map[string]interface{}) (map[string]interface{}, error) {
+	if t.nextCache == nil {
+		return t.translateEntity(sourceEntity, dictEntry)
+	}
+
+	inputHash := t.entityInputHash(hashValue(sourceEntity), dictEntry)
+	if cached, ok := t.cache.entity(cacheKey); ok && cached.InputHash == inputHash && len(cached.Output) > 0 {
+		var translatedEntity map[string]interface{}
+		if err := json.Unmarshal(cached.Output, &translatedEntity); err == nil {
+			t.nextCache.setEntity(cacheKey, cached)
+			return translatedEntity, nil
 		}
 	}
 
-	fmt.Printf("Created %d translated backgrounds\n", len(translatedBackgrounds))
+	translatedEntity, err := t.translateEntity(sourceEntity, dictEntry)
+	if err != nil {
+		return nil, err
+	}
+	output, err := json.Marshal(translatedEntity)
+	if err != nil {
+		return nil, err
+	}
+	t.nextCache.setEntity(cacheKey, entityCacheEntry{InputHash: inputHash, Output: output})
+	return translatedEntity, nil
+}
+
+// entityInputHash returns the hash of everything the merged output of an entity
+// depends on, given the hash of its source
+func (t *Translator) entityInputHash(sourceHash string, dictEntry map[string]interface{}) string {
+	return hashStrings(Version, t.optionsDigest, sourceHash, hashValue(dictEntry), t.references.hash(dictEntry["entries"]))
+}
 
-	// Update the translated data with the processed backgrounds
-	translatedData["background"] = translatedBackgrounds
+// translateEntity applies a dictionary entry to a copy of its source entity
+func (t *Translator) translateEntity(sourceEntity, dictEntry map[string]interface{}) (map[string]interface{}, error) {
+	// Create a deep copy of the source entity
+	clone, err := cloneValue(sourceEntity)
+	if err != nil {
+		return nil, err
+	}
+	translatedEntity := clone.(map[string]interface{})
+
+	// Apply translations from dictionary; strings no layer translates keep the source text
+	if translatedName, exists := dictEntry["name"]; exists {
+		translatedEntity["name"] = fillFromSource(translatedName, sourceEntity["name"])
+	}
+	if name, ok := translatedEntity["name"].(string); ok {
+		original, _ := sourceEntity["name"].(string)
+		translatedEntity["name"] = FormatName(t.options.NamingPattern, name, original)
+	}
+	if t.options.GenerateAliases {
+		if aliases := entityAliases(translatedEntity, sourceEntity, dictEntry); len(aliases) > 0 {
+			translatedEntity["alias"] = aliases
+		} else {
+			delete(translatedEntity, "alias")
+		}
+	}
+
+	// Apply entries translation if present
+	if dictEntries, exists := dictEntry["entries"]; exists {
+		translatedEntity["entries"], err = cloneValue(dictEntries)
+		if err != nil {
+			return nil, err
+		}
+		translatedEntity["entries"] = fillFromSource(translatedEntity["entries"], sourceEntity["entries"])
+		if t.options.FixCrossReferences {
+			translatedEntity["entries"] = t.references.fix(translatedEntity["entries"])
+		}
+	}
 
-	return translatedData, nil
+	return translatedEntity, nil
 }
 
-// writeTranslatedData writes the translated data to the export directory
-func (t *Translator) writeTranslatedData(data map[string]interface{}) error {
+// writeTranslatedData writes the translated data to a file in the export directory
+func (t *Translator) writeTranslatedData(file string, data map[string]interface{}) error {
 	// Ensure export directory exists
-	err := os.MkdirAll(t.exportPath, 0755)
+	outputPath := filepath.Join(t.exportPath, file)
+	err := os.MkdirAll(filepath.Dir(outputPath), 0755)
 	if err != nil {
 		return fmt.Errorf("failed to create export directory: %w", err)
 	}
@@ -211,7 +420,6 @@ func (t *Translator) writeTranslatedData(data map[string]interface{}) error {
 	}
 
 	// Write to file
-	outputPath := filepath.Join(t.exportPath, "backgrounds.json")
 	err = ioutil.WriteFile(outputPath, jsonData, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write translated data: %w", err)
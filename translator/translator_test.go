@@ -67,17 +67,18 @@ func TestLoadDictionaryEntries(t *testing.T) {
 	}
 
 	translator := NewTranslator("", tempDir, "")
-	dictionaryEntries, err := translator.loadDictionaryEntries()
+	index, err := translator.loadDictionaryIndex()
 
 	if err != nil {
 		t.Fatalf("Failed to load dictionary data: %v", err)
 	}
 
+	dictionaryEntries := index.entries["background"]
 	if len(dictionaryEntries) != 1 {
-		t.Errorf("Expected 1 dictionary entry, got %d", len(dictionaryEntries))
+		t.Fatalf("Expected 1 dictionary entry, got %d", len(dictionaryEntries))
 	}
 
-	entry := dictionaryEntries[0]
+	entry := dictionaryEntries["Acolyte|XPHB"]
 	if entry["origin_name"] != "Acolyte" {
 		t.Errorf("Expected origin_name to be 'Acolyte', got '%s'", entry["origin_name"])
 	}
@@ -126,7 +127,7 @@ func TestLoadSourceData(t *testing.T) {
 	}
 
 	translator := NewTranslator(tempDir, "", "")
-	loadedData, err := translator.loadSourceData()
+	loadedData, err := translator.loadSourceData("backgrounds.json")
 
 	if err != nil {
 		t.Fatalf("Failed to load source data: %v", err)
@@ -192,12 +193,14 @@ func TestApplyTranslations(t *testing.T) {
 	}
 
 	translator := NewTranslator("", "", "")
-	translatedData, err := translator.applyTranslations(sourceData, dictionaryEntries)
+	result, err := translator.applyTranslations("background", sourceData, indexEntries("background", dictionaryEntries).entries["background"])
 
 	if err != nil {
 		t.Fatalf("Failed to apply translations: %v", err)
 	}
 
+	translatedData := result.data
+
 	backgrounds, exists := translatedData["background"]
 	if !exists {
 		t.Fatalf("Expected 'background' field in translated data")
@@ -231,6 +234,15 @@ func TestApplyTranslations(t *testing.T) {
 	}
 }
 
+// indexEntries builds a dictionary index from entries of a single category
+func indexEntries(category string, entries []map[string]interface{}) *dictionaryIndex {
+	index := newDictionaryIndex()
+	for _, entry := range entries {
+		index.add(category, entry)
+	}
+	return index
+}
+
 func TestWriteTranslatedData(t *testing.T) {
 	// Create temporary test directory
 	tempDir, err := ioutil.TempDir("", "test_export")
@@ -255,7 +267,7 @@ func TestWriteTranslatedData(t *testing.T) {
 	}
 
 	translator := NewTranslator("", "", tempDir)
-	err = translator.writeTranslatedData(data)
+	err = translator.writeTranslatedData("backgrounds.json", data)
 
 	if err != nil {
 		t.Fatalf("Failed to write translated data: %v", err)
@@ -12,18 +12,18 @@ func run(window *app.Window) error {
 	theme := material.NewTheme()
 	var ops op.Ops
 
-	var currentWindow layouts.LayoutWindow = &layouts.LayoutMain{}
-	currentWindow.Init(window)
+	nav := layouts.NewNavigator(window, &layouts.LayoutMain{})
 
 	for {
 		e := window.Event()
 		// exp.ListenEvents(e)
 		switch e := e.(type) {
 		case app.DestroyEvent:
+			nav.Close()
 			return e.Err
 		case app.FrameEvent:
 			gtx := app.NewContext(&ops, e)
-			currentWindow = currentWindow.FrameEventHandler(theme, gtx)
+			nav.Layout(theme, gtx)
 			e.Frame(gtx.Ops)
 		}
 	}